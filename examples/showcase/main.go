@@ -3,11 +3,17 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/fiwippi/go-quantise/pkg/colours"
 	"github.com/fiwippi/go-quantise/pkg/quantisers"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/lmq"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/mediancut"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/neuquant"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/octree"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/otsu"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/pnn"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/pnnlab"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/pnnoklab"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/wu"
 	"image"
 	"log"
 )
@@ -35,15 +41,29 @@ func main() {
 	LMQExample()
 	PNNExample()
 	PNNLABExample()
+	PNNOKLabExample()
+	MedianCutExample()
+	OctreeExample()
+	WuExample()
+	NeuQuantExample()
+	DitherExample()
 }
 
 func OtsuExample() {
 	fmt.Println("Creating Otsu...")
 
+	// Greyscale Image Single Tone
 	colours := otsu.QuantiseGreyscale(img)
 	quantisedImg, _ := quantisers.ImageFromPalette(img, colours, quantisers.NoDither)
 	SaveJPEG("otsu-grey.jpg", quantisedImg)
 
+	// Greyscale Image Multi Tone, one less threshold than colours
+	colours = otsu.QuantiseGreyscaleMulti(img, paletteSize-1)
+	quantisedImg, _ = quantisers.ImageFromPalette(img, colours, quantisers.NoDither)
+	palette := quantisers.ColourPaletteImage(colours, 200)
+	SaveJPEG("otsu-grey-multi.jpg", quantisedImg)
+	SaveJPEG("otsu-grey-multi-palette.jpg", palette)
+
 	fmt.Println("Finished Otsu")
 }
 
@@ -105,12 +125,121 @@ func PNNLABExample() {
 
 	// Doesn't do greyscale because same as PNN
 
+	// Remaps using the same metric the palette was built with
+	opts := quantisers.Options{Dither: quantisers.NoDither, Metric: colours.CIEDE2000}
+
 	// Colour Image Multi Tone
 	colours := pnnlab.QuantiseColour(img, paletteSize)
 	quantisedImg, _ := quantisers.ImageFromPalette(img, colours, quantisers.NoDither)
+	remappedImg, _ := quantisers.ImageFromPaletteWithOpts(img, colours, opts)
 	palette := quantisers.ColourPaletteImage(colours, 200)
 	SaveJPEG("pnnlab-colour-multi.jpg", quantisedImg)
+	SaveJPEG("pnnlab-colour-multi-remapped.jpg", remappedImg)
 	SaveJPEG("pnnlab-colour-multi-palette.jpg", palette)
 
 	fmt.Println("Finished PNN LAB")
 }
+
+func PNNOKLabExample() {
+	fmt.Println("Creating PNN OKLab...")
+
+	// Remaps using the same metric the palette was built with
+	opts := quantisers.Options{Dither: quantisers.NoDither, Metric: colours.OKLabDistance}
+
+	// Colour Image Multi Tone
+	colours := pnnoklab.QuantiseColour(img, paletteSize)
+	quantisedImg, _ := quantisers.ImageFromPaletteWithOpts(img, colours, opts)
+	palette := quantisers.ColourPaletteImage(colours, 200)
+	SaveJPEG("pnnoklab-colour-multi.jpg", quantisedImg)
+	SaveJPEG("pnnoklab-colour-multi-palette.jpg", palette)
+
+	fmt.Println("Finished PNN OKLab")
+}
+
+func MedianCutExample() {
+	fmt.Println("Creating Median Cut...")
+
+	// Colour Image Multi Tone
+	colours := mediancut.QuantiseColour(img, paletteSize)
+	quantisedImg, _ := quantisers.ImageFromPalette(img, colours, quantisers.NoDither)
+	palette := quantisers.ColourPaletteImage(colours, 200)
+	SaveJPEG("mediancut-colour-multi.jpg", quantisedImg)
+	SaveJPEG("mediancut-colour-multi-palette.jpg", palette)
+
+	fmt.Println("Finished Median Cut")
+}
+
+func OctreeExample() {
+	fmt.Println("Creating Octree...")
+
+	// Colour Image Multi Tone
+	colours := octree.QuantiseColour(img, paletteSize)
+	quantisedImg, _ := quantisers.ImageFromPalette(img, colours, quantisers.NoDither)
+	palette := quantisers.ColourPaletteImage(colours, 200)
+	SaveJPEG("octree-colour-multi.jpg", quantisedImg)
+	SaveJPEG("octree-colour-multi-palette.jpg", palette)
+
+	fmt.Println("Finished Octree")
+}
+
+func WuExample() {
+	fmt.Println("Creating Wu...")
+
+	// Colour Image Multi Tone
+	colours := wu.QuantiseColour(img, paletteSize)
+	quantisedImg, _ := quantisers.ImageFromPalette(img, colours, quantisers.NoDither)
+	palette := quantisers.ColourPaletteImage(colours, 200)
+	SaveJPEG("wu-colour-multi.jpg", quantisedImg)
+	SaveJPEG("wu-colour-multi-palette.jpg", palette)
+
+	fmt.Println("Finished Wu")
+}
+
+func NeuQuantExample() {
+	fmt.Println("Creating NeuQuant...")
+
+	// Colour Image Multi Tone
+	colours := neuquant.QuantiseColour(img, paletteSize)
+	quantisedImg, _ := quantisers.ImageFromPalette(img, colours, quantisers.NoDither)
+	palette := quantisers.ColourPaletteImage(colours, 200)
+	SaveJPEG("neuquant-colour-multi.jpg", quantisedImg)
+	SaveJPEG("neuquant-colour-multi-palette.jpg", palette)
+
+	fmt.Println("Finished NeuQuant")
+}
+
+func DitherExample() {
+	fmt.Println("Creating Dithers...")
+
+	dithers := map[string]quantisers.DitherType{
+		"jarvisjudiceninke": quantisers.JarvisJudiceNinke,
+		"stucki":            quantisers.Stucki,
+		"burkes":            quantisers.Burkes,
+		"sierra3":           quantisers.Sierra3,
+		"sierra2":           quantisers.Sierra2,
+		"sierralite":        quantisers.SierraLite,
+		"atkinson":          quantisers.Atkinson,
+		"stevensonarce":     quantisers.StevensonArce,
+		"bluenoise":         quantisers.BlueNoise,
+	}
+
+	maps := map[string]quantisers.ThresholdMap{
+		"bayer16x16":   quantisers.BayerMatrix(16),
+		"clustereddot": quantisers.ClusteredDot(6),
+		"lines":        quantisers.HorizontalLines(4),
+	}
+
+	// Colour Image Multi Tone
+	colours := pnn.QuantiseColour(img, paletteSize)
+	for name, ditherType := range dithers {
+		ditheredImg, _ := quantisers.ImageFromPalette(img, colours, ditherType)
+		SaveJPEG("pnn-colour-multi-dithered-"+name+".jpg", ditheredImg)
+	}
+	for name, m := range maps {
+		opts := quantisers.Options{Dither: quantisers.CustomThresholdMap, ThresholdMap: m}
+		ditheredImg, _ := quantisers.ImageFromPaletteWithOpts(img, colours, opts)
+		SaveJPEG("pnn-colour-multi-dithered-"+name+".jpg", ditheredImg)
+	}
+
+	fmt.Println("Finished Dithers")
+}
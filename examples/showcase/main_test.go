@@ -1,10 +1,18 @@
 package main
 
 import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers/kapur"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/kittler"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/kmeans"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/lmq"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/mediancut"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/neuquant"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/octree"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/otsu"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/pnn"
 	"github.com/fiwippi/go-quantise/pkg/quantisers/pnnlab"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/pnnoklab"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/wu"
 	"testing"
 )
 
@@ -14,6 +22,18 @@ func BenchmarkOtsuGreySingle(b *testing.B) {
 	otsu.QuantiseGreyscale(benchImg)
 }
 
+func BenchmarkOtsuGreyMulti(b *testing.B) {
+	otsu.QuantiseGreyscaleMulti(benchImg, 6)
+}
+
+func BenchmarkKapurGreySingle(b *testing.B) {
+	kapur.QuantiseGreyscale(benchImg)
+}
+
+func BenchmarkKittlerGreySingle(b *testing.B) {
+	kittler.QuantiseGreyscale(benchImg)
+}
+
 func BenchmarkLMQGreySingle(b *testing.B) {
 	lmq.QuantiseGreyscale(benchImg, 1)
 }
@@ -37,3 +57,27 @@ func BenchmarkPNNColourMulti(b *testing.B) {
 func BenchmarkPNNLABColourMulti(b *testing.B) {
 	pnnlab.QuantiseColour(benchImg, 6)
 }
+
+func BenchmarkPNNOKLabColourMulti(b *testing.B) {
+	pnnoklab.QuantiseColour(benchImg, 6)
+}
+
+func BenchmarkMedianCutColourMulti(b *testing.B) {
+	mediancut.QuantiseColour(benchImg, 6)
+}
+
+func BenchmarkOctreeColourMulti(b *testing.B) {
+	octree.QuantiseColour(benchImg, 6)
+}
+
+func BenchmarkPNNKMeansColourMulti(b *testing.B) {
+	kmeans.Refine(benchImg, pnn.QuantiseColour(benchImg, 6), kmeans.Options{})
+}
+
+func BenchmarkWuColourMulti(b *testing.B) {
+	wu.QuantiseColour(benchImg, 6)
+}
+
+func BenchmarkNeuQuantColourMulti(b *testing.B) {
+	neuquant.QuantiseColour(benchImg, 6)
+}
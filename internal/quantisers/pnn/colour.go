@@ -3,6 +3,7 @@ package pnn
 import (
 	"container/heap"
 	"github.com/fiwippi/go-quantise/pkg/colours"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
 	"image"
 	"image/color"
 	"math"
@@ -11,25 +12,78 @@ import (
 
 // Used as a variable for each pnn operation to determine what type of distance calculation to use,
 // this variable is available for the whole scope of the pnn operation
-type PNNMode uint8
+type PNNMode struct {
+	metric colours.Metric // If nil then the merge cost in RGB space is used
+}
 
 // Which colour mode to use when calculating the distances between colours
-const (
-	RGB PNNMode = iota
-	LAB
+var (
+	RGB   = PNNMode{}
+	LAB   = PNNMode{colours.CIEDE2000}
+	OKLab = PNNMode{colours.OKLabDistance}
+
+	// Reproduces the palettes of LAB mode from earlier versions
+	LegacyLAB = PNNMode{colours.LegacyCIEDE2000}
 )
 
-// Quantises a given into a palette of "m" colours to best represent it
+// Creates a mode which uses the metric to calculate the distances between colours,
+// unlike RGB mode the distance isn't weighted by the number of pixels in each node
+func WithMetric(metric colours.Metric) PNNMode {
+	return PNNMode{metric}
+}
+
+// Quantises a given into a palette of "m" colours to best represent it,
+// if the image has less than "m" colours then all of them are returned
 func (mode PNNMode) QuantiseColour(img image.Image, M int) color.Palette {
+	nodes := mode.Clusters(img, M)
+
+	thresholds := make(color.Palette, len(nodes))
+	for i, n := range nodes {
+		thresholds[i] = n.Colour()
+	}
+
+	return thresholds
+}
+
+// Returns the palette of "m" colours along with how many pixels each colour represents,
+// the swatches are sorted so that the most prominent colour comes first
+func (mode PNNMode) Dominant(img image.Image, M int) []quantisers.Swatch {
+	nodes := mode.Clusters(img, M)
+
+	var total float64
+	for _, n := range nodes {
+		total += n.N
+	}
+
+	swatches := make([]quantisers.Swatch, len(nodes))
+	for i, n := range nodes {
+		swatches[i] = quantisers.Swatch{
+			Colour:    n.Colour(),
+			Count:     int(n.N),
+			Fraction:  n.N / total,
+			MeanError: math.Max(n.E, 0) / n.N,
+		}
+	}
+	quantisers.SortSwatches(swatches)
+
+	return swatches
+}
+
+// Clusters the colours of the image into "m" nodes, each node holds the mean colour and
+// population of its cluster. If the image has less than "m" colours then all of them are returned
+func (mode PNNMode) Clusters(img image.Image, M int) []*Node {
 	// Creates the histogram of the image
-	hist := CreatePNNHistogram(img)
+	hist := mode.histogram(img)
+	if M < 1 || len(hist) == 0 {
+		return nil
+	}
 
 	// Make the linked list of nodes
 	S, H := mode.initialiseColours(hist)
 
 	m := H.Len() + 1
 	count := 0
-	for m != M {
+	for m > M && H.Len() > 0 {
 		n := mode.recalculateNeighbours(H, count)
 		mode.updateColourStructs(n, n.NN, H, count)
 
@@ -37,14 +91,22 @@ func (mode PNNMode) QuantiseColour(img image.Image, M int) color.Palette {
 		count += 1
 	}
 
-	thresholds := make(color.Palette, 0, M)
+	nodes := make([]*Node, 0, m)
 	for S != nil {
-		clr := color.RGBA{uint8(S.R), uint8(S.G), uint8(S.B), uint8(S.A)}
-		thresholds = append(thresholds, clr)
+		nodes = append(nodes, S)
 		S = S.Next
 	}
 
-	return thresholds
+	return nodes
+}
+
+// Creates the histogram of the image, planar images in the colour space of the
+// metric are binned by their points so that their pixels aren't converted
+func (mode PNNMode) histogram(img image.Image) Histogram {
+	if points, ok := colours.PlanarPoints(img, mode.metric); ok {
+		return CreatePointHistogram(points)
+	}
+	return CreatePNNHistogram(img)
 }
 
 // Recalculates nearest neighbours
@@ -84,6 +146,8 @@ func (mode PNNMode) initialiseColours(hist Histogram) (*Node, *Heap) {
 		currentNode.R /= currentNode.N
 		currentNode.G /= currentNode.N
 		currentNode.B /= currentNode.N
+		currentNode.E -= currentNode.N * (Sqr(currentNode.A) + Sqr(currentNode.R) + Sqr(currentNode.G) + Sqr(currentNode.B))
+		mode.convert(currentNode)
 
 		currentNode.Prev = previousNode
 		if previousNode != nil {
@@ -116,19 +180,17 @@ func (mode PNNMode) nearestNeighbour(node *Node) {
 	var err = math.MaxFloat64
 	var nn *Node
 
-	if mode == LAB {
-		lab1 := node.LAB()
+	if mode.metric != nil {
 		tmp := node.Next
 		for tmp != nil {
-			lab2 := tmp.LAB()
-			nerr := colours.LABDistance(lab1, lab2)
+			nerr := mode.metric.Distance(node.P, tmp.P)
 			if nerr < err {
 				err = nerr
 				nn = tmp
 			}
 			tmp = tmp.Next
 		}
-	} else { // Default to RGB if not LAB or any other mode
+	} else { // Default to RGB if there is no metric
 		tmp := node.Next
 		for tmp != nil {
 			nerr := VectorCost(node, tmp)
@@ -144,14 +206,26 @@ func (mode PNNMode) nearestNeighbour(node *Node) {
 	node.D = err
 }
 
+// Converts the colour of the node into a point for the metric, this is done
+// once per colour so that finding the nearest neighbour doesn't repeat it
+func (mode PNNMode) convert(node *Node) {
+	if mode.metric != nil {
+		node.P = mode.metric.Convert(&node.RGB)
+	}
+}
+
 // Reduces the size of the linked list to eventually achieve a quantised palette
 func (mode PNNMode) updateColourStructs(a, b *Node, h *Heap, count int) {
+	// The merge cost in RGBA is exactly the increase in the squared error
+	a.E += b.E + VectorCost(a, b)
+
 	Nq := a.N + b.N
 	a.A = (a.N*a.A + b.N*b.A) / Nq
 	a.R = (a.N*a.R + b.N*b.R) / Nq
 	a.G = (a.N*a.G + b.N*b.G) / Nq
 	a.B = (a.N*a.B + b.N*b.B) / Nq
 	a.N = Nq
+	mode.convert(a)
 
 	// Unchain the nearest neighbour bin
 	if b.Next != nil {
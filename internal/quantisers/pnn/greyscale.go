@@ -8,9 +8,13 @@ import (
 	"sort"
 )
 
-// Returns "m" greyscale colours to best recreate the colour palette of the original image
+// Returns "m" greyscale colours to best recreate the colour palette of the original image,
+// if the image has less than "m" grey levels then all of them are returned
 func QuantiseGreyscale(img image.Image, m int) color.Palette {
 	hist := quantisers.CreateGreyscaleHistogram(img)
+	if m < 1 || len(hist) == 0 {
+		return nil
+	}
 	T := calculateGreyscaleThresholds(hist, m)
 
 	colours := make([]color.Color, len(T))
@@ -30,14 +34,14 @@ func calculateGreyscaleThresholds(hist quantisers.LinearHistogram, M int) []int
 	// in the list is left out from the heap so for total
 	// number of elements, 1 needs to be added
 	m := H.Len() + 1
-	for m != M {
+	for m > M && H.Len() > 0 {
 		sa := H.Front().(*Node)
 		updateGreyscaleStructs(sa, sa.Next, H)
 		m = m - 1
 	}
 
 	// Return the greyscale thresholds
-	thresholds := make([]int, 0, M)
+	thresholds := make([]int, 0, m)
 	for S != nil {
 		thresholds = append(thresholds, int(S.C))
 		S = S.Next
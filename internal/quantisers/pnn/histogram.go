@@ -1,6 +1,10 @@
 package pnn
 
-import "image"
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image"
+	"math"
+)
 
 // Histogram for PNN Nodes which are coloured
 type Histogram map[uint32]*Node
@@ -39,8 +43,136 @@ func CreatePNNHistogram(img image.Image) Histogram {
 			pixels[index].G += float64(g)
 			pixels[index].B += float64(b)
 			pixels[index].N++
+
+			// Holds the sum of squares until the node is initialised,
+			// at which point it becomes the sum of squared errors
+			pixels[index].E += Sqr(float64(a)) + Sqr(float64(r)) + Sqr(float64(g)) + Sqr(float64(b))
+		}
+	}
+
+	return pixels
+}
+
+// Number of bins along each component of a point histogram, the same number as each channel
+// of an RGB histogram has. More bins slow PNN down and give it more outliers to keep
+const pointBins = 16
+
+// Creates a PNN Histogram from the points of a planar image, each component is split into
+// bins across its range in the image. Only a few points of each bin are converted into RGB
+// so none of the pixels are converted. The error within each bin is estimated from the spread
+// of its points, which is carried into RGB by the rate the colour changes around the bin's mean.
+// The bins are in the colour space of the image so the palette differs slightly from the one
+// created from the same image in RGB
+func CreatePointHistogram(img colours.PointImage) Histogram {
+	bounds := img.Bounds()
+	width, height := bounds.Max.X, bounds.Max.Y
+
+	// Find the range of each component
+	lo := colours.Point{math.Inf(1), math.Inf(1), math.Inf(1)}
+	hi := colours.Point{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			p := img.PointAt(x, y)
+			for c := range p {
+				lo[c], hi[c] = math.Min(lo[c], p[c]), math.Max(hi[c], p[c])
+			}
+		}
+	}
+
+	type bin struct {
+		sum colours.Point
+		sq  [3][3]float64 // Sum of the products of every pair of components
+		n   float64
+	}
+	bins := make(map[uint32]*bin)
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			p := img.PointAt(x, y)
+
+			var index uint32
+			for c := range p {
+				q := 0
+				if hi[c] > lo[c] {
+					q = int((p[c] - lo[c]) / (hi[c] - lo[c]) * pointBins)
+				}
+				if q > pointBins-1 {
+					q = pointBins - 1
+				}
+				index = index*pointBins + uint32(q)
+			}
+
+			b := bins[index]
+			if b == nil {
+				b = &bin{}
+				bins[index] = b
+			}
+			for c := range p {
+				b.sum[c] += p[c]
+				for d := c; d < len(p); d++ {
+					b.sq[c][d] += p[c] * p[d]
+				}
+			}
+			b.n++
 		}
 	}
 
+	// Nodes hold the sums of the bin's colour like those of an RGB histogram
+	pixels := make(Histogram, len(bins))
+	for index, b := range bins {
+		mean := colours.Point{b.sum[0] / b.n, b.sum[1] / b.n, b.sum[2] / b.n}
+		colour := pointRGBA(img, mean)
+
+		// The squared error of the bin is the covariance of its points carried into RGB,
+		// the rate of change along each component is taken across the bin's width
+		var J [3][4]float64
+		for c := range mean {
+			h := (hi[c] - lo[c]) / pointBins / 2
+			if h == 0 {
+				continue
+			}
+			p1, p2 := mean, mean
+			p1[c] -= h
+			p2[c] += h
+			c1, c2 := pointRGBA(img, p1), pointRGBA(img, p2)
+			for k := range J[c] {
+				J[c][k] = (c2[k] - c1[k]) / (2 * h)
+			}
+		}
+		var sse float64
+		for c := range mean {
+			for d := range mean {
+				cov := b.sq[minInt(c, d)][maxInt(c, d)]/b.n - mean[c]*mean[d]
+				for k := range colour {
+					sse += J[c][k] * J[d][k] * cov
+				}
+			}
+		}
+
+		n := &Node{N: b.n}
+		n.A, n.R, n.G, n.B = colour[3]*b.n, colour[0]*b.n, colour[1]*b.n, colour[2]*b.n
+		n.E = (Sqr(n.A)+Sqr(n.R)+Sqr(n.G)+Sqr(n.B))/b.n + math.Max(sse, 0)*b.n
+		pixels[index] = n
+	}
+
 	return pixels
 }
+
+// Returns the RGBA values of the point in the range 0-255, they aren't rounded
+func pointRGBA(img colours.PointImage, p colours.Point) [4]float64 {
+	r, g, b, a := img.Colour(p).RGBA()
+	return [4]float64{float64(r) / 257, float64(g) / 257, float64(b) / 257, float64(a) / 257}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
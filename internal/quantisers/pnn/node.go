@@ -3,6 +3,7 @@ package pnn
 import (
 	_ "fmt"
 	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image/color"
 	"math"
 )
 
@@ -19,11 +20,18 @@ type Node struct {
 	T uint8   // Maximal grey value, also serves as threshold between the class and its neighbour class to the right
 
 	// Variables for colour quantisation
-	colours.RGB         // RGB Values of the node
-	A           float64 // Alpha Value of the node (Used for non-LAB PNN)
-	NN          *Node   // Pointers to the nearest neighbour
-	MergeCount  int     // The iteration where the node was last merged with another
-	UpdateCount int     // The iteration where the MSE was last calculated for the node
+	colours.RGB               // RGB Values of the node
+	A           float64       // Alpha Value of the node (Used for non-LAB PNN)
+	NN          *Node         // Pointers to the nearest neighbour
+	MergeCount  int           // The iteration where the node was last merged with another
+	UpdateCount int           // The iteration where the MSE was last calculated for the node
+	E           float64       // Sum of squared errors of the pixels in the class from its mean colour
+	P           colours.Point // The RGB values converted by the metric used for PNN, if one is used
+}
+
+// Returns the mean colour of the node
+func (n *Node) Colour() color.Color {
+	return color.RGBA{uint8(n.R), uint8(n.G), uint8(n.B), uint8(n.A)}
 }
 
 // Squares a float64 number
@@ -0,0 +1,66 @@
+package quantisetest
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Colours used by the tests of the quantisers
+var (
+	Red   = color.RGBA{R: 255, A: 255}
+	Green = color.RGBA{G: 255, A: 255}
+	Blue  = color.RGBA{B: 255, A: 255}
+)
+
+// Returns a 4x4 image made up of the given colours in equal stripes
+func Stripes(c ...color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 4*len(c), 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4*len(c); x++ {
+			img.Set(x, y, c[x/4])
+		}
+	}
+	return img
+}
+
+// Returns a 4x4 image made up of the given grey levels in equal stripes
+func GreyStripes(levels ...uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, 4*len(levels), 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4*len(levels); x++ {
+			img.SetGray(x, y, color.Gray{Y: levels[x/4]})
+		}
+	}
+	return img
+}
+
+// Input which every quantiser should handle the same way
+type EdgeCase struct {
+	Name string
+	Img  image.Image
+	M    int
+	Want int // Number of colours in the palette
+	Err  error
+}
+
+// Returns the edge cases for colour quantisers, or for greyscale quantisers if grey is true
+func EdgeCases(grey bool) []EdgeCase {
+	one, three := Stripes(Red), Stripes(Red, Green, Blue)
+	if grey {
+		one, three = GreyStripes(100), GreyStripes(0, 100, 200)
+	}
+
+	return []EdgeCase{
+		{"zero m", three, 0, 0, quantisers.ErrInvalidPaletteSize},
+		{"negative m", three, -1, 0, quantisers.ErrInvalidPaletteSize},
+		{"empty image", image.NewRGBA(image.Rect(0, 0, 0, 0)), 1, 0, quantisers.ErrEmptyImage},
+		{"nil image", nil, 1, 0, quantisers.ErrEmptyImage},
+		{"single colour", one, 1, 1, nil},
+		{"single colour with larger m", one, 4, 1, nil},
+		{"m below colour count", three, 2, 2, nil},
+		{"m above colour count", three, 10, 3, nil},
+		{"huge m", three, math.MaxInt32, 3, nil},
+	}
+}
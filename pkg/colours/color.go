@@ -0,0 +1,13 @@
+package colours
+
+import "image/color"
+
+// Converts a color.Color to RGB, alpha is ignored
+func toRGB(c color.Color) *RGB {
+	if rgb, ok := c.(RGB); ok {
+		return &rgb
+	}
+
+	r, g, b, _ := c.RGBA()
+	return &RGB{float64(r) / 257, float64(g) / 257, float64(b) / 257}
+}
@@ -0,0 +1,317 @@
+package colours
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// Reference values for the sRGB primaries, white and black under D65
+var references = []struct {
+	rgb RGB
+	xyz XYZ
+	lab LAB
+}{
+	{RGB{0, 0, 0}, XYZ{0, 0, 0}, LAB{0, 0, 0}},
+	{RGB{255, 255, 255}, XYZ{0.9505, 1, 1.0888}, LAB{100, 0, 0}},
+	{RGB{255, 0, 0}, XYZ{0.4125, 0.2127, 0.0193}, LAB{53.2408, 80.0925, 67.2032}},
+	{RGB{0, 255, 0}, XYZ{0.3576, 0.7152, 0.1192}, LAB{87.7347, -86.1827, 83.1793}},
+	{RGB{0, 0, 255}, XYZ{0.1804, 0.0722, 0.9503}, LAB{32.2970, 79.1875, -107.8602}},
+	{RGB{128, 128, 128}, XYZ{0.2052, 0.2159, 0.2351}, LAB{53.5850, 0, 0}},
+}
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestRGBToXYZ(t *testing.T) {
+	for _, ref := range references {
+		xyz := ref.rgb.XYZ()
+		if !near(xyz.X, ref.xyz.X, 1e-4) || !near(xyz.Y, ref.xyz.Y, 1e-4) || !near(xyz.Z, ref.xyz.Z, 1e-4) {
+			t.Errorf("%v: got XYZ %v, want %v", ref.rgb, *xyz, ref.xyz)
+		}
+	}
+}
+
+func TestRGBToLAB(t *testing.T) {
+	for _, ref := range references {
+		lab := ref.rgb.LAB()
+		if !near(lab.L, ref.lab.L, 1e-2) || !near(lab.A, ref.lab.A, 1e-2) || !near(lab.B, ref.lab.B, 1e-2) {
+			t.Errorf("%v: got LAB %v, want %v", ref.rgb, *lab, ref.lab)
+		}
+	}
+}
+
+func TestLABToRGB(t *testing.T) {
+	for _, ref := range references {
+		rgb := ref.lab.RGB()
+		if !near(rgb.R, ref.rgb.R, 0.5) || !near(rgb.G, ref.rgb.G, 0.5) || !near(rgb.B, ref.rgb.B, 0.5) {
+			t.Errorf("%v: got RGB %v, want %v", ref.lab, *rgb, ref.rgb)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for r := 0.0; r < 256; r += 15 {
+		for g := 0.0; g < 256; g += 15 {
+			for b := 0.0; b < 256; b += 15 {
+				rgb := &RGB{r, g, b}
+				if got := rgb.LAB().RGB(); !near(got.R, r, 1e-3) || !near(got.G, g, 1e-3) || !near(got.B, b, 1e-3) {
+					t.Errorf("%v: LAB round trip gave %v", *rgb, *got)
+				}
+			}
+		}
+	}
+}
+
+func TestSpacesRoundTrip(t *testing.T) {
+	spaces := map[string]func(rgb *RGB) *RGB{
+		"HSV":   func(rgb *RGB) *RGB { return rgb.HSV().RGB() },
+		"HSL":   func(rgb *RGB) *RGB { return rgb.HSL().RGB() },
+		"HWB":   func(rgb *RGB) *RGB { return rgb.HWB().RGB() },
+		"YCbCr": func(rgb *RGB) *RGB { return rgb.YCbCr().RGB() },
+		"LUV":   func(rgb *RGB) *RGB { return rgb.LUV().RGB() },
+		"LCh":   func(rgb *RGB) *RGB { return rgb.LCh().RGB() },
+		"OKLab": func(rgb *RGB) *RGB { return rgb.OKLab().RGB() },
+	}
+
+	for name, convert := range spaces {
+		for r := 0.0; r < 256; r += 15 {
+			for g := 0.0; g < 256; g += 15 {
+				for b := 0.0; b < 256; b += 15 {
+					rgb := &RGB{r, g, b}
+					if got := convert(rgb); !near(got.R, r, 1e-2) || !near(got.G, g, 1e-2) || !near(got.B, b, 1e-2) {
+						t.Errorf("%v: %s round trip gave %v", *rgb, name, *got)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSpacesReference(t *testing.T) {
+	orange := &RGB{255, 128, 0}
+	if hsv := orange.HSV(); !near(hsv.H, 30.1, 0.1) || !near(hsv.S, 1, 1e-4) || !near(hsv.V, 1, 1e-4) {
+		t.Errorf("got HSV %v", *hsv)
+	}
+	if hsl := orange.HSL(); !near(hsl.H, 30.1, 0.1) || !near(hsl.S, 1, 1e-4) || !near(hsl.L, 0.5, 1e-4) {
+		t.Errorf("got HSL %v", *hsl)
+	}
+	if hwb := orange.HWB(); !near(hwb.H, 30.1, 0.1) || !near(hwb.W, 0, 1e-4) || !near(hwb.B, 0, 1e-4) {
+		t.Errorf("got HWB %v", *hwb)
+	}
+
+	// CIELUV of sRGB red under D65
+	if luv := (&RGB{255, 0, 0}).LUV(); !near(luv.L, 53.2408, 1e-2) || !near(luv.U, 175.0151, 5e-2) || !near(luv.V, 37.7564, 5e-2) {
+		t.Errorf("got LUV %v", *luv)
+	}
+}
+
+func TestModels(t *testing.T) {
+	models := []color.Model{
+		RGBModel, XYZModel, LABModel, LChModel, LUVModel, OKLabModel,
+		OKLChModel, HSVModel, HSLModel, HWBModel, YCbCrModel,
+	}
+
+	c := color.RGBA{R: 200, G: 100, B: 50, A: 255}
+	for _, model := range models {
+		r, g, b, _ := model.Convert(c).RGBA()
+		if r>>8 != 200 || g>>8 != 100 || b>>8 != 50 {
+			t.Errorf("%T: got %d %d %d", model.Convert(c), r>>8, g>>8, b>>8)
+		}
+	}
+}
+
+func TestRGBA(t *testing.T) {
+	if r, g, b, a := (RGB{255, 0, 128}).RGBA(); r != 0xffff || g != 0 || b != 0x8080 || a != 0xffff {
+		t.Errorf("got %#x %#x %#x %#x", r, g, b, a)
+	}
+	if r, g, b, _ := (LAB{100, 0, 0}).RGBA(); r>>8 != 0xff || g>>8 != 0xff || b>>8 != 0xff {
+		t.Errorf("got %#x %#x %#x for LAB white", r, g, b)
+	}
+}
+
+func TestLABImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(1, 1, 4, 3))
+	img.Set(2, 2, color.RGBA{R: 255, A: 255})
+
+	lab := ToLABImage(img)
+	if lab.Bounds() != img.Bounds() {
+		t.Fatalf("got bounds %v, want %v", lab.Bounds(), img.Bounds())
+	}
+	if c := lab.LABAt(2, 2); !near(c.L, 53.2408, 1e-2) || !near(c.A, 80.0925, 1e-2) || !near(c.B, 67.2032, 1e-2) {
+		t.Errorf("got LAB %v for red", c)
+	}
+	if r, g, b, _ := lab.At(1, 1).RGBA(); r != 0 || g != 0 || b != 0 {
+		t.Errorf("got %d %d %d for black", r, g, b)
+	}
+}
+
+func TestPlanar(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+
+	planars := []PointImage{ToLABImage(img), ToOKLabImage(img), ToLinearRGBImage(img)}
+	for _, p := range planars {
+		if r, g, b, _ := p.At(1, 1).RGBA(); r>>8 != 200 || g>>8 != 100 || b>>8 != 50 {
+			t.Errorf("%T: got %d %d %d", p, r>>8, g>>8, b>>8)
+		}
+		if r, g, b, _ := p.Colour(p.PointAt(1, 1)).RGBA(); r>>8 != 200 || g>>8 != 100 || b>>8 != 50 {
+			t.Errorf("%T: got colour %d %d %d", p, r>>8, g>>8, b>>8)
+		}
+	}
+
+	lab := ToLABImage(img)
+	if _, ok := PlanarPoints(lab, CIEDE2000); !ok {
+		t.Error("CIEDE2000 should read points from a LAB image")
+	}
+	if _, ok := PlanarPoints(lab, OKLabDistance); ok {
+		t.Error("OKLab distance shouldn't read points from a LAB image")
+	}
+
+	want := CIEDE2000.Convert(&RGB{200, 100, 50})
+	if got := lab.PointAt(1, 1); !near(got[0], want[0], 1e-3) || !near(got[1], want[1], 1e-3) || !near(got[2], want[2], 1e-3) {
+		t.Errorf("got point %v, want %v", got, want)
+	}
+}
+
+func TestWorkingSpaces(t *testing.T) {
+	// XYZ of the red primary of each space and of white under D65
+	references := []struct {
+		ws       *WorkingSpace
+		red, xyz XYZ
+	}{
+		{SRGB, XYZ{0.4124, 0.2126, 0.0193}, XYZ{0.9505, 1, 1.0891}},
+		{LinearSRGB, XYZ{0.4124, 0.2126, 0.0193}, XYZ{0.9505, 1, 1.0891}},
+		{DisplayP3, XYZ{0.4866, 0.2290, 0}, XYZ{0.9505, 1, 1.0891}},
+		{Rec2020, XYZ{0.6370, 0.2627, 0}, XYZ{0.9505, 1, 1.0891}},
+	}
+
+	for _, ref := range references {
+		red, white := ref.ws.XYZ(&RGB{255, 0, 0}), ref.ws.XYZ(&RGB{255, 255, 255})
+		if !near(red.X, ref.red.X, 1e-3) || !near(red.Y, ref.red.Y, 1e-3) || !near(red.Z, ref.red.Z, 1e-3) {
+			t.Errorf("got red %v, want %v", *red, ref.red)
+		}
+		if !near(white.X, ref.xyz.X, 1e-3) || !near(white.Y, ref.xyz.Y, 1e-3) || !near(white.Z, ref.xyz.Z, 1e-3) {
+			t.Errorf("got white %v, want %v", *white, ref.xyz)
+		}
+		if rgb := ref.ws.RGB(red); !near(rgb.R, 255, 1e-6) || !near(rgb.G, 0, 1e-6) || !near(rgb.B, 0, 1e-6) {
+			t.Errorf("got RGB %v from red", *rgb)
+		}
+	}
+
+	// Display P3 red is outside of sRGB so it's clamped
+	if rgb := DisplayP3.Convert(&RGB{255, 0, 0}, SRGB); !near(rgb.R, 255, 1e-6) || !near(rgb.G, 0, 1e-6) || !near(rgb.B, 0, 1e-6) {
+		t.Errorf("got sRGB %v from Display P3 red", *rgb)
+	}
+	// Colours in both gamuts convert back and forth
+	if rgb := SRGB.Convert(DisplayP3.Convert(&RGB{120, 60, 200}, SRGB), DisplayP3); !near(rgb.R, 120, 1e-6) || !near(rgb.G, 60, 1e-6) || !near(rgb.B, 200, 1e-6) {
+		t.Errorf("got Display P3 %v after round trip", *rgb)
+	}
+}
+
+func TestBradford(t *testing.T) {
+	// D65 white adapted to D50 is the D50 white
+	d50 := D65.XYZ().Adapt(D65, D50)
+	want := D50.XYZ()
+	if !near(d50.X, want.X, 1e-4) || !near(d50.Y, want.Y, 1e-4) || !near(d50.Z, want.Z, 1e-4) {
+		t.Errorf("got %v, want %v", *d50, *want)
+	}
+
+	// Lindbloom's Bradford adaptation of sRGB red from D65 to D50
+	red := (&XYZ{0.4124564, 0.2126729, 0.0193339}).Adapt(D65, D50)
+	if !near(red.X, 0.4360747, 1e-3) || !near(red.Y, 0.2225045, 1e-3) || !near(red.Z, 0.0139322, 1e-3) {
+		t.Errorf("got adapted red %v", *red)
+	}
+}
+
+func TestMetricInSpace(t *testing.T) {
+	m := MetricInSpace(CIEDE2000, DisplayP3)
+	if got, want := m.Convert(&RGB{255, 255, 255}), CIEDE2000.Convert(&RGB{255, 255, 255}); !near(got[0], want[0], 1e-2) {
+		t.Errorf("got white %v, want %v", got, want)
+	}
+	if MetricInSpace(CIEDE2000, SRGB) != CIEDE2000 {
+		t.Error("metrics in sRGB shouldn't be wrapped")
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	// Greys are seen the same with every deficiency
+	for _, d := range Deficiencies {
+		if rgb := (&RGB{128, 128, 128}).Simulate(d); !near(rgb.R, 128, 0.5) || !near(rgb.G, 128, 0.5) || !near(rgb.B, 128, 0.5) {
+			t.Errorf("%v: got %v for grey", d, *rgb)
+		}
+	}
+
+	p := color.Palette{
+		color.RGBA{R: 220, G: 40, B: 40, A: 255},
+		color.RGBA{G: 160, A: 255},
+		color.RGBA{R: 30, G: 80, B: 220, A: 255},
+	}
+	if simulated := SimulatePalette(p, Deuteranopia); simulated[0].(color.NRGBA).A != 255 {
+		t.Errorf("alpha wasn't kept, got %v", simulated[0])
+	}
+
+	// Red and green are confused with deuteranopia but not blue
+	var found bool
+	for _, pair := range Indistinguishable(p, 10) {
+		if pair.I == 2 || pair.J == 2 {
+			t.Errorf("%v: blue shouldn't be confused, got %+v", pair.Deficiency, pair)
+		}
+		if pair.Deficiency == Deuteranopia && pair.I == 0 && pair.J == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("red and green should be confused with deuteranopia")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	// Pairs of LAB colours from Sharma et al. (2005) and the distances between them
+	pairs := []struct {
+		lab1, lab2       Point
+		cie76, ciede2000 float64
+	}{
+		{Point{50, 2.6772, -79.7751}, Point{50, 0, -82.7485}, 4.0010, 2.0425},
+		{Point{50, 0, 0}, Point{50, -1, 2}, 2.2361, 2.3669},
+		{Point{50, 2.5, 0}, Point{73, 25, -18}, 36.8680, 27.1492},
+	}
+	for i, p := range pairs {
+		if d := CIE76.Distance(p.lab1, p.lab2); !near(d, p.cie76, 1e-4) {
+			t.Errorf("%d: got CIE76 %.4f, want %.4f", i, d, p.cie76)
+		}
+		if d := CIEDE2000.Distance(p.lab1, p.lab2); !near(d, p.ciede2000, 1e-4) {
+			t.Errorf("%d: got CIEDE2000 %.4f, want %.4f", i, d, p.ciede2000)
+		}
+	}
+
+	black, white := &RGB{0, 0, 0}, &RGB{255, 255, 255}
+	references := map[string]struct {
+		m    Metric
+		want float64
+	}{
+		"Redmean": {Redmean, 764.8334},
+		"OKLab":   {OKLabDistance, 1},
+		"CIE76":   {CIE76, 100},
+		"CIE94":   {CIE94, 100},
+	}
+	for name, ref := range references {
+		if d := ref.m.Distance(ref.m.Convert(black), ref.m.Convert(white)); !near(d, ref.want, 1e-3) {
+			t.Errorf("%s: got %.4f between black and white, want %.4f", name, d, ref.want)
+		}
+	}
+
+	// Every metric measures no difference between a colour and itself
+	metrics := []Metric{CIE76, CIE94, CIE94Textiles, CMC(2, 1), CMC(1, 1), CIEDE2000, Redmean, OKLabDistance, LegacyCIEDE2000}
+	orange, teal := &RGB{230, 120, 20}, &RGB{20, 130, 140}
+	for i, m := range metrics {
+		if d := m.Distance(m.Convert(orange), m.Convert(orange)); !near(d, 0, 1e-9) {
+			t.Errorf("%d: got %v between the same colours", i, d)
+		}
+		if d := m.Distance(m.Convert(orange), m.Convert(teal)); d <= 0 {
+			t.Errorf("%d: got %v between different colours", i, d)
+		}
+	}
+}
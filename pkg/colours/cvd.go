@@ -0,0 +1,120 @@
+package colours
+
+import "image/color"
+
+// Colour vision deficiency
+type Deficiency int
+
+const (
+	Protanopia   Deficiency = iota // No red cones
+	Deuteranopia                   // No green cones
+	Tritanopia                     // No blue cones
+)
+
+// Every deficiency which can be simulated
+var Deficiencies = []Deficiency{Protanopia, Deuteranopia, Tritanopia}
+
+func (d Deficiency) String() string {
+	switch d {
+	case Protanopia:
+		return "protanopia"
+	case Deuteranopia:
+		return "deuteranopia"
+	case Tritanopia:
+		return "tritanopia"
+	default:
+		return "unknown deficiency"
+	}
+}
+
+// Machado, Oliveira and Fernandes (2009) simulation matrices at full severity, these are applied to linear RGB
+var machado = [...]matrix{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// Simulates how the colour is seen by someone with the deficiency
+func (rgb *RGB) Simulate(d Deficiency) *RGB {
+	lin := rgb.LinearRGB()
+
+	// The matrix is applied to the linear RGB channels as a vector
+	v := machado[d].apply(&XYZ{lin.R, lin.G, lin.B})
+	return (&LinearRGB{v.X, v.Y, v.Z}).RGB()
+}
+
+// Simulates how each colour of the palette is seen by someone with the deficiency, alpha is kept
+func SimulatePalette(p color.Palette, d Deficiency) color.Palette {
+	simulated := make(color.Palette, len(p))
+	for i := range p {
+		rgb, a := unpremultiplied(p[i])
+		rgb = rgb.Simulate(d)
+		simulated[i] = color.NRGBA{
+			R: ClampFloatToUint8(rgb.R + 0.5),
+			G: ClampFloatToUint8(rgb.G + 0.5),
+			B: ClampFloatToUint8(rgb.B + 0.5),
+			A: a,
+		}
+	}
+
+	return simulated
+}
+
+// Pair of palette colours which can be told apart with normal vision but not with a deficiency
+type ConfusedPair struct {
+	Deficiency Deficiency
+	I, J       int     // Indices of the colours in the palette
+	Normal     float64 // CIEDE2000 difference of the colours
+	Simulated  float64 // CIEDE2000 difference of the colours when seen with the deficiency
+}
+
+// Returns every pair of palette colours whose CIEDE2000 difference is at least the threshold, but which
+// falls below it when the colours are seen with any of the deficiencies. A threshold around 10 flags
+// colours which are hard to tell apart in charts, small values such as 2.3 only flag near identical ones
+func Indistinguishable(p color.Palette, threshold float64) []ConfusedPair {
+	normal := paletteLAB(p)
+
+	var pairs []ConfusedPair
+	for _, d := range Deficiencies {
+		simulated := paletteLAB(SimulatePalette(p, d))
+		for i := 0; i < len(p); i++ {
+			for j := i + 1; j < len(p); j++ {
+				n := LABDistance(normal[i], normal[j])
+				s := LABDistance(simulated[i], simulated[j])
+				if n >= threshold && s < threshold {
+					pairs = append(pairs, ConfusedPair{d, i, j, n, s})
+				}
+			}
+		}
+	}
+
+	return pairs
+}
+
+// Converts every colour of the palette to LAB
+func paletteLAB(p color.Palette) []*LAB {
+	labs := make([]*LAB, len(p))
+	for i := range p {
+		rgb, _ := unpremultiplied(p[i])
+		labs[i] = rgb.LAB()
+	}
+	return labs
+}
+
+// Returns the colour without its alpha premultiplied, along with its alpha
+func unpremultiplied(c color.Color) (*RGB, uint8) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return &RGB{float64(n.R), float64(n.G), float64(n.B)}, n.A
+}
@@ -0,0 +1,54 @@
+package colours
+
+import (
+	"image/color"
+	"math"
+)
+
+// HSL Colour, the hue is in degrees and the saturation and lightness are in the range [0, 1]
+type HSL struct {
+	H, S, L float64
+}
+
+// Model which converts colours to HSL
+var HSLModel = color.ModelFunc(hslModel)
+
+func hslModel(c color.Color) color.Color {
+	if _, ok := c.(HSL); ok {
+		return c
+	}
+	return *toRGB(c).HSL()
+}
+
+// Converts an RGB colour to HSL
+func (rgb *RGB) HSL() *HSL {
+	r, g, b := rgb.scale()
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l := (max + min) / 2
+
+	var s float64
+	if max != min {
+		s = (max - min) / (1 - math.Abs(2*l-1))
+	}
+
+	return &HSL{hue(r, g, b, max, min), s, l}
+}
+
+// Converts an HSL colour to RGB
+func (hsl *HSL) RGB() *RGB {
+	a := hsl.S * math.Min(hsl.L, 1-hsl.L)
+	channel := func(n float64) float64 {
+		k := math.Mod(n+hsl.H/30, 12)
+		if k < 0 {
+			k += 12
+		}
+		return (hsl.L - a*math.Max(-1, math.Min(k-3, math.Min(9-k, 1)))) * 255
+	}
+
+	return &RGB{channel(0), channel(8), channel(4)}
+}
+
+// Implements color.Color
+func (hsl HSL) RGBA() (r, g, b, a uint32) {
+	return hsl.RGB().RGBA()
+}
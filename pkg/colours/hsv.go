@@ -0,0 +1,77 @@
+package colours
+
+import (
+	"image/color"
+	"math"
+)
+
+// HSV Colour, the hue is in degrees and the saturation and value are in the range [0, 1]
+type HSV struct {
+	H, S, V float64
+}
+
+// Model which converts colours to HSV
+var HSVModel = color.ModelFunc(hsvModel)
+
+func hsvModel(c color.Color) color.Color {
+	if _, ok := c.(HSV); ok {
+		return c
+	}
+	return *toRGB(c).HSV()
+}
+
+// Converts an RGB colour to HSV
+func (rgb *RGB) HSV() *HSV {
+	r, g, b := rgb.scale()
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+
+	var s float64
+	if max > 0 {
+		s = (max - min) / max
+	}
+
+	return &HSV{hue(r, g, b, max, min), s, max}
+}
+
+// Converts an HSV colour to RGB
+func (hsv *HSV) RGB() *RGB {
+	channel := func(n float64) float64 {
+		k := math.Mod(n+hsv.H/60, 6)
+		if k < 0 {
+			k += 6
+		}
+		return (hsv.V - hsv.V*hsv.S*math.Max(0, math.Min(k, math.Min(4-k, 1)))) * 255
+	}
+
+	return &RGB{channel(5), channel(3), channel(1)}
+}
+
+// Implements color.Color
+func (hsv HSV) RGBA() (r, g, b, a uint32) {
+	return hsv.RGB().RGBA()
+}
+
+// Returns the hue in degrees of the scaled RGB colour, given the maximum and minimum
+// of its channels, if the colour is grey then the hue is zero
+func hue(r, g, b, max, min float64) float64 {
+	delta := max - min
+	if delta == 0 {
+		return 0
+	}
+
+	var h float64
+	switch max {
+	case r:
+		h = math.Mod((g-b)/delta, 6)
+	case g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
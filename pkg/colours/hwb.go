@@ -0,0 +1,45 @@
+package colours
+
+import (
+	"image/color"
+	"math"
+)
+
+// HWB Colour, the hue is in degrees and the whiteness and blackness are in the range [0, 1]
+type HWB struct {
+	H, W, B float64
+}
+
+// Model which converts colours to HWB
+var HWBModel = color.ModelFunc(hwbModel)
+
+func hwbModel(c color.Color) color.Color {
+	if _, ok := c.(HWB); ok {
+		return c
+	}
+	return *toRGB(c).HWB()
+}
+
+// Converts an RGB colour to HWB
+func (rgb *RGB) HWB() *HWB {
+	r, g, b := rgb.scale()
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+
+	return &HWB{hue(r, g, b, max, min), min, 1 - max}
+}
+
+// Converts an HWB colour to RGB, if the whiteness and blackness
+// add up to more than one then they're scaled to add up to one
+func (hwb *HWB) RGB() *RGB {
+	if hwb.W+hwb.B >= 1 {
+		grey := hwb.W / (hwb.W + hwb.B) * 255
+		return &RGB{grey, grey, grey}
+	}
+
+	return (&HSV{hwb.H, 1 - hwb.W/(1-hwb.B), 1 - hwb.B}).RGB()
+}
+
+// Implements color.Color
+func (hwb HWB) RGBA() (r, g, b, a uint32) {
+	return hwb.RGB().RGBA()
+}
@@ -1,12 +1,30 @@
 package colours
 
-import "math"
+import (
+	"image/color"
+	"math"
+)
 
 // LAB Colour
 type LAB struct {
 	L, A, B float64
 }
 
+// Model which converts colours to LAB
+var LABModel = color.ModelFunc(labModel)
+
+func labModel(c color.Color) color.Color {
+	if _, ok := c.(LAB); ok {
+		return c
+	}
+	return *toRGB(c).LAB()
+}
+
+// Implements color.Color
+func (lab LAB) RGBA() (r, g, b, a uint32) {
+	return lab.RGB().RGBA()
+}
+
 // Converts a LAB colour to XYZ
 func (lab *LAB) XYZ() *XYZ {
 	x := Xn * finv(((lab.L+16)/116)+(lab.A/500))
@@ -128,5 +146,5 @@ func finv(t float64) float64 {
 
 // Constants for LAB conversion
 const (
-	d float64 = 6 / 29
+	d float64 = 6.0 / 29
 )
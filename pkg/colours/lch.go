@@ -0,0 +1,49 @@
+package colours
+
+import (
+	"image/color"
+	"math"
+)
+
+// LCh Colour, the polar form of LAB where the hue is in degrees
+type LCh struct {
+	L, C, H float64
+}
+
+// Model which converts colours to LCh
+var LChModel = color.ModelFunc(lchModel)
+
+func lchModel(c color.Color) color.Color {
+	if _, ok := c.(LCh); ok {
+		return c
+	}
+	return *toRGB(c).LCh()
+}
+
+// Converts an RGB colour to the LCh colour space
+func (rgb *RGB) LCh() *LCh {
+	return rgb.LAB().LCh()
+}
+
+// Converts a LAB colour to LCh
+func (lab *LAB) LCh() *LCh {
+	c := math.Sqrt(Sqr(lab.A) + Sqr(lab.B))
+	h := math.Mod(math.Atan2(lab.B, lab.A)*(180/math.Pi)+360, 360)
+
+	return &LCh{lab.L, c, h}
+}
+
+// Converts an LCh colour to LAB
+func (lch *LCh) LAB() *LAB {
+	return &LAB{lch.L, lch.C * Cos(lch.H), lch.C * Sin(lch.H)}
+}
+
+// Converts an LCh colour to RGB
+func (lch *LCh) RGB() *RGB {
+	return lch.LAB().RGB()
+}
+
+// Implements color.Color
+func (lch LCh) RGBA() (r, g, b, a uint32) {
+	return lch.RGB().RGBA()
+}
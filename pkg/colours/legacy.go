@@ -0,0 +1,42 @@
+package colours
+
+import "math"
+
+// Converts an RGB colour to LAB the way earlier versions of this package did, the sRGB transfer
+// curve isn't removed, the white point is scaled for XYZ in the range [0, 100] although XYZ is in
+// the range [0, 1] and black converts to NaN. Only use this to reproduce earlier results
+func (rgb *RGB) LegacyLAB() *LAB {
+	r, g, b := rgb.scale()
+
+	x := 0.4124564*r + 0.3575761*g + 0.1804375*b
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := 0.0193339*r + 0.1191920*g + 0.9503041*b
+
+	return &LAB{
+		116*legacyF(y/100) - 16,
+		500 * (legacyF(x/95.0489) - legacyF(y/100)),
+		200 * (legacyF(y/100) - legacyF(z/109.8840)),
+	}
+}
+
+// The threshold which f used to have, 6 / 29 is evaluated using integer division so it's zero
+const legacyD float64 = 6 / 29
+
+func legacyF(t float64) float64 {
+	if t > math.Pow(legacyD, 3) {
+		return math.Cbrt(t)
+	}
+	return t/(3*math.Pow(legacyD, 2)) + float64(4)/float64(29)
+}
+
+// CIEDE2000 on colours converted using LegacyLAB
+type legacyCIEDE2000 struct{}
+
+func (legacyCIEDE2000) Convert(rgb *RGB) Point {
+	lab := rgb.LegacyLAB()
+	return Point{lab.L, lab.A, lab.B}
+}
+
+func (legacyCIEDE2000) Distance(p1, p2 Point) float64 {
+	return LABDistance(&LAB{p1[0], p1[1], p1[2]}, &LAB{p2[0], p2[1], p2[2]})
+}
@@ -0,0 +1,39 @@
+package colours
+
+import "image/color"
+
+// Linear RGB Colour, the sRGB transfer curve is removed so the
+// components are proportional to light, range 0-1
+type LinearRGB struct {
+	R, G, B float64
+}
+
+// Model which converts colours to linear RGB
+var LinearRGBModel = color.ModelFunc(linearRGBModel)
+
+func linearRGBModel(c color.Color) color.Color {
+	if _, ok := c.(LinearRGB); ok {
+		return c
+	}
+	return *toRGB(c).LinearRGB()
+}
+
+// Converts an RGB colour to linear RGB
+func (rgb *RGB) LinearRGB() *LinearRGB {
+	r, g, b := rgb.scale()
+	return &LinearRGB{linearise(r), linearise(g), linearise(b)}
+}
+
+// Converts a linear RGB colour to RGB, colours outside of the sRGB gamut are clamped
+func (lin *LinearRGB) RGB() *RGB {
+	return &RGB{
+		compand(Clamp(lin.R, 0, 1)) * 255,
+		compand(Clamp(lin.G, 0, 1)) * 255,
+		compand(Clamp(lin.B, 0, 1)) * 255,
+	}
+}
+
+// Implements color.Color
+func (lin LinearRGB) RGBA() (r, g, b, a uint32) {
+	return lin.RGB().RGBA()
+}
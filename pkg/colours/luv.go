@@ -0,0 +1,71 @@
+package colours
+
+import "image/color"
+
+// CIELUV Colour
+type LUV struct {
+	L, U, V float64
+}
+
+// Model which converts colours to LUV
+var LUVModel = color.ModelFunc(luvModel)
+
+func luvModel(c color.Color) color.Color {
+	if _, ok := c.(LUV); ok {
+		return c
+	}
+	return *toRGB(c).LUV()
+}
+
+// Converts an RGB colour to the LUV colour space
+func (rgb *RGB) LUV() *LUV {
+	return rgb.XYZ().LUV()
+}
+
+// Converts an XYZ colour to the LUV colour space
+func (xyz *XYZ) LUV() *LUV {
+	L := 116*f(xyz.Y/Yn) - 16
+
+	denom := xyz.X + 15*xyz.Y + 3*xyz.Z
+	if denom == 0 {
+		return &LUV{L, 0, 0}
+	}
+	un, vn := whiteUV()
+	u := 13 * L * (4*xyz.X/denom - un)
+	v := 13 * L * (9*xyz.Y/denom - vn)
+
+	return &LUV{L, u, v}
+}
+
+// Converts a LUV colour to XYZ
+func (luv *LUV) XYZ() *XYZ {
+	if luv.L <= 0 {
+		return &XYZ{0, 0, 0}
+	}
+
+	un, vn := whiteUV()
+	u := luv.U/(13*luv.L) + un
+	v := luv.V/(13*luv.L) + vn
+
+	y := Yn * finv((luv.L+16)/116)
+	x := y * 9 * u / (4 * v)
+	z := y * (12 - 3*u - 20*v) / (4 * v)
+
+	return &XYZ{x, y, z}
+}
+
+// Converts a LUV colour to RGB
+func (luv *LUV) RGB() *RGB {
+	return luv.XYZ().RGB()
+}
+
+// Implements color.Color
+func (luv LUV) RGBA() (r, g, b, a uint32) {
+	return luv.RGB().RGBA()
+}
+
+// Returns the chromaticity coordinates u' and v' of the white point
+func whiteUV() (u, v float64) {
+	denom := Xn + 15*Yn + 3*Zn
+	return 4 * Xn / denom, 9 * Yn / denom
+}
@@ -0,0 +1,224 @@
+package colours
+
+import (
+	"image"
+	"math"
+)
+
+// Point is a colour converted into the space a metric measures distances in
+type Point [3]float64
+
+// Metric measures the perceived difference between two colours. Colours are converted
+// into points once, so that comparing one colour against many others is cheap
+type Metric interface {
+	// Converts an RGB colour into a point in the space of the metric
+	Convert(rgb *RGB) Point
+	// Distance between two points converted by the metric
+	Distance(p1, p2 Point) float64
+}
+
+// Available metrics, CMC has its own constructor since its weights can be chosen
+var (
+	CIE76         Metric = cie76{}
+	CIE94         Metric = cie94{kL: 1, k1: 0.045, k2: 0.015} // Uses the graphic arts weights
+	CIE94Textiles Metric = cie94{kL: 2, k1: 0.048, k2: 0.014}
+	CIEDE2000     Metric = ciede2000{}
+	Redmean       Metric = redmean{}
+	OKLabDistance Metric = oklabDistance{}
+
+	// CIEDE2000 using the LAB conversion of earlier versions, see RGB.LegacyLAB
+	LegacyCIEDE2000 Metric = legacyCIEDE2000{}
+)
+
+// Metrics whose points are the components of a planar image type
+type planarMetric interface {
+	reads(img image.Image) bool
+}
+
+// Returns the image as a PointImage if it is planar and in the colour space the metric
+// measures distances in, so the metric's points can be read without converting any pixels
+func PlanarPoints(img image.Image, m Metric) (PointImage, bool) {
+	if pm, ok := m.(planarMetric); ok && pm.reads(img) {
+		return img.(PointImage), true
+	}
+	return nil, false
+}
+
+// Metrics which can convert XYZ colours, so colours from any working space can be measured
+type xyzMetric interface {
+	convertXYZ(xyz *XYZ) Point
+}
+
+// Metric which measures colours from a working space other than sRGB
+type spaceMetric struct {
+	Metric
+	ws *WorkingSpace
+}
+
+// Returns the metric for measuring colours in the working space instead of sRGB. Metrics
+// which measure distances in LAB or OKLab convert colours through XYZ so nothing is lost,
+// other metrics measure the colours after they're converted into sRGB
+func MetricInSpace(m Metric, ws *WorkingSpace) Metric {
+	if ws == nil || ws == SRGB {
+		return m
+	}
+	return spaceMetric{m, ws}
+}
+
+func (m spaceMetric) Convert(rgb *RGB) Point {
+	if xm, ok := m.Metric.(xyzMetric); ok {
+		return xm.convertXYZ(m.ws.XYZ(rgb))
+	}
+	return m.Metric.Convert(m.ws.Convert(rgb, SRGB))
+}
+
+// Metrics which measure distances in LAB space
+type labMetric struct{}
+
+func (labMetric) Convert(rgb *RGB) Point {
+	return labPoint(rgb)
+}
+
+func (labMetric) convertXYZ(xyz *XYZ) Point {
+	lab := xyz.LAB()
+	return Point{lab.L, lab.A, lab.B}
+}
+
+func (labMetric) reads(img image.Image) bool {
+	_, ok := img.(*LABImage)
+	return ok
+}
+
+// Euclidean distance in LAB space
+type cie76 struct {
+	labMetric
+}
+
+func (cie76) Distance(p1, p2 Point) float64 {
+	return CIE76Distance(&LAB{p1[0], p1[1], p1[2]}, &LAB{p2[0], p2[1], p2[2]})
+}
+
+// CIE94 - https://en.wikipedia.org/wiki/Color_difference#CIE94
+type cie94 struct {
+	labMetric
+	kL, k1, k2 float64
+}
+
+func (m cie94) Distance(p1, p2 Point) float64 {
+	return cie94Distance(&LAB{p1[0], p1[1], p1[2]}, &LAB{p2[0], p2[1], p2[2]}, m.kL, m.k1, m.k2)
+}
+
+// CMC l:c - https://en.wikipedia.org/wiki/Color_difference#CMC_l:c_(1984)
+type cmc struct {
+	labMetric
+	l, c float64
+}
+
+// Returns the CMC l:c metric, 2:1 is commonly used for acceptability and 1:1 for perceptibility
+func CMC(l, c float64) Metric {
+	return cmc{l: l, c: c}
+}
+
+func (m cmc) Distance(p1, p2 Point) float64 {
+	return CMCDistance(&LAB{p1[0], p1[1], p1[2]}, &LAB{p2[0], p2[1], p2[2]}, m.l, m.c)
+}
+
+// CIEDE2000 using LABDistance
+type ciede2000 struct {
+	labMetric
+}
+
+func (ciede2000) Distance(p1, p2 Point) float64 {
+	return LABDistance(&LAB{p1[0], p1[1], p1[2]}, &LAB{p2[0], p2[1], p2[2]})
+}
+
+// Weighted euclidean distance in RGB space which approximates
+// perceived differences - https://www.compuphase.com/cmetric.htm
+type redmean struct{}
+
+func (redmean) Convert(rgb *RGB) Point {
+	return Point{rgb.R, rgb.G, rgb.B}
+}
+
+func (redmean) Distance(p1, p2 Point) float64 {
+	rMean := (p1[0] + p2[0]) / 2
+	dr, dg, db := p1[0]-p2[0], p1[1]-p2[1], p1[2]-p2[2]
+	return math.Sqrt((2+rMean/256)*Sqr(dr) + 4*Sqr(dg) + (2+(255-rMean)/256)*Sqr(db))
+}
+
+// Euclidean distance in OKLab space - https://bottosson.github.io/posts/oklab/
+type oklabDistance struct{}
+
+func (oklabDistance) Convert(rgb *RGB) Point {
+	return oklabPoint(rgb)
+}
+
+func (oklabDistance) convertXYZ(xyz *XYZ) Point {
+	lab := xyz.OKLab()
+	return Point{lab.L, lab.A, lab.B}
+}
+
+func (oklabDistance) reads(img image.Image) bool {
+	_, ok := img.(*OKLabImage)
+	return ok
+}
+
+func (oklabDistance) Distance(p1, p2 Point) float64 {
+	return math.Sqrt(Sqr(p1[0]-p2[0]) + Sqr(p1[1]-p2[1]) + Sqr(p1[2]-p2[2]))
+}
+
+// CIE76 - Euclidean distance between two LAB colours
+func CIE76Distance(lab1, lab2 *LAB) float64 {
+	return math.Sqrt(Sqr(lab1.L-lab2.L) + Sqr(lab1.A-lab2.A) + Sqr(lab1.B-lab2.B))
+}
+
+// CIE94 using the graphic arts weights, the distance isn't symmetric
+// since the chroma of the first colour is used as the reference
+func CIE94Distance(lab1, lab2 *LAB) float64 {
+	return cie94Distance(lab1, lab2, 1, 0.045, 0.015)
+}
+
+func cie94Distance(lab1, lab2 *LAB, kL, k1, k2 float64) float64 {
+	C1 := math.Sqrt(Sqr(lab1.A) + Sqr(lab1.B))
+	C2 := math.Sqrt(Sqr(lab2.A) + Sqr(lab2.B))
+
+	deltaL := lab1.L - lab2.L
+	deltaC := C1 - C2
+	deltaH2 := math.Max(Sqr(lab1.A-lab2.A)+Sqr(lab1.B-lab2.B)-Sqr(deltaC), 0)
+
+	Sc := 1 + k1*C1
+	Sh := 1 + k2*C1
+
+	return math.Sqrt(Sqr(deltaL/kL) + Sqr(deltaC/Sc) + deltaH2/Sqr(Sh))
+}
+
+// CMC l:c, the distance isn't symmetric since the first colour is used as the reference
+func CMCDistance(lab1, lab2 *LAB, l, c float64) float64 {
+	C1 := math.Sqrt(Sqr(lab1.A) + Sqr(lab1.B))
+	C2 := math.Sqrt(Sqr(lab2.A) + Sqr(lab2.B))
+
+	deltaL := lab1.L - lab2.L
+	deltaC := C1 - C2
+	deltaH2 := math.Max(Sqr(lab1.A-lab2.A)+Sqr(lab1.B-lab2.B)-Sqr(deltaC), 0)
+
+	var Sl float64
+	if lab1.L < 16 {
+		Sl = 0.511
+	} else {
+		Sl = (0.040975 * lab1.L) / (1 + 0.01765*lab1.L)
+	}
+	Sc := (0.0638*C1)/(1+0.0131*C1) + 0.638
+
+	H1 := math.Mod(math.Atan2(lab1.B, lab1.A)*(180/math.Pi)+360, 360)
+	var T float64
+	if H1 >= 164 && H1 <= 345 {
+		T = 0.56 + math.Abs(0.2*Cos(H1+168))
+	} else {
+		T = 0.36 + math.Abs(0.4*Cos(H1+35))
+	}
+	C14 := math.Pow(C1, 4)
+	F := math.Sqrt(C14 / (C14 + 1900))
+	Sh := Sc * (F*T + 1 - F)
+
+	return math.Sqrt(Sqr(deltaL/(l*Sl)) + Sqr(deltaC/(c*Sc)) + deltaH2/Sqr(Sh))
+}
@@ -0,0 +1,95 @@
+package colours
+
+import (
+	"image/color"
+	"math"
+)
+
+// OKLab Colour - https://bottosson.github.io/posts/oklab/
+type OKLab struct {
+	L, A, B float64
+}
+
+// OKLCh Colour, the polar form of OKLab where the hue is in degrees
+type OKLCh struct {
+	L, C, H float64
+}
+
+// Models which convert colours to OKLab and OKLCh
+var (
+	OKLabModel = color.ModelFunc(oklabModel)
+	OKLChModel = color.ModelFunc(oklchModel)
+)
+
+func oklabModel(c color.Color) color.Color {
+	if _, ok := c.(OKLab); ok {
+		return c
+	}
+	return *toRGB(c).OKLab()
+}
+
+func oklchModel(c color.Color) color.Color {
+	if _, ok := c.(OKLCh); ok {
+		return c
+	}
+	return *toRGB(c).OKLCh()
+}
+
+// Implements color.Color
+func (lab OKLab) RGBA() (r, g, b, a uint32) {
+	return lab.RGB().RGBA()
+}
+
+// Implements color.Color
+func (lch OKLCh) RGBA() (r, g, b, a uint32) {
+	return lch.RGB().RGBA()
+}
+
+// Converts an XYZ colour to the OKLab colour space
+func (xyz *XYZ) OKLab() *OKLab {
+	l := math.Cbrt(0.8189330101*xyz.X + 0.3618667424*xyz.Y - 0.1288597137*xyz.Z)
+	m := math.Cbrt(0.0329845436*xyz.X + 0.9293118715*xyz.Y + 0.0361456387*xyz.Z)
+	s := math.Cbrt(0.0482003018*xyz.X + 0.2643662691*xyz.Y + 0.6338517070*xyz.Z)
+
+	return &OKLab{
+		0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// Converts an OKLab colour to RGB, colours outside of the sRGB gamut are clamped
+func (lab *OKLab) RGB() *RGB {
+	l := lab.L + 0.3963377774*lab.A + 0.2158037573*lab.B
+	m := lab.L - 0.1055613458*lab.A - 0.0638541728*lab.B
+	s := lab.L - 0.0894841775*lab.A - 1.2914855480*lab.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	r := 4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	g := -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	b := -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+
+	return &RGB{
+		compand(Clamp(r, 0, 1)) * 255,
+		compand(Clamp(g, 0, 1)) * 255,
+		compand(Clamp(b, 0, 1)) * 255,
+	}
+}
+
+// Converts an OKLab colour to OKLCh
+func (lab *OKLab) OKLCh() *OKLCh {
+	c := math.Sqrt(Sqr(lab.A) + Sqr(lab.B))
+	h := math.Mod(math.Atan2(lab.B, lab.A)*(180/math.Pi)+360, 360)
+
+	return &OKLCh{lab.L, c, h}
+}
+
+// Converts an OKLCh colour to OKLab
+func (lch *OKLCh) OKLab() *OKLab {
+	return &OKLab{lch.L, lch.C * Cos(lch.H), lch.C * Sin(lch.H)}
+}
+
+// Converts an OKLCh colour to RGB, colours outside of the sRGB gamut are clamped
+func (lch *OKLCh) RGB() *RGB {
+	return lch.OKLab().RGB()
+}
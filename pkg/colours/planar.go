@@ -0,0 +1,217 @@
+package colours
+
+import (
+	"image"
+	"image/color"
+)
+
+// LABImage, OKLabImage and LinearRGBImage store each of their three float32 colour components
+// in a separate plane, so an image only has to be converted into a colour space once and its
+// pixels can then be read as points without any more conversions. Alpha is ignored when an
+// image is converted
+
+// PointImage is an image whose pixels can be read as points in its colour space
+type PointImage interface {
+	image.Image
+	PointAt(x, y int) Point
+	// Returns the colour of a point in the image's colour space
+	Colour(p Point) color.Color
+}
+
+// Planes shared by every planar image type
+type planes struct {
+	// Pix holds the planes of each component, the components of the pixel at
+	// (x, y) are at index (y-Rect.Min.Y)*Stride + (x-Rect.Min.X) of each plane
+	Pix [3][]float32
+	// Stride is the number of pixels between vertically adjacent pixels
+	Stride int
+	// Rect is the image's bounds
+	Rect image.Rectangle
+}
+
+func newPlanes(r image.Rectangle) planes {
+	n := r.Dx() * r.Dy()
+	return planes{
+		Pix:    [3][]float32{make([]float32, n), make([]float32, n), make([]float32, n)},
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// Fills the planes by converting every pixel of the image
+func (p *planes) fill(img image.Image, convert func(rgb *RGB) Point) {
+	i := 0
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			pt := convert(toRGB(img.At(x, y)))
+			p.Pix[0][i], p.Pix[1][i], p.Pix[2][i] = float32(pt[0]), float32(pt[1]), float32(pt[2])
+			i++
+		}
+	}
+}
+
+func (p *planes) Bounds() image.Rectangle {
+	return p.Rect
+}
+
+// Returns the index of the pixel at (x, y) in each plane
+func (p *planes) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Returns the components of the pixel at (x, y), or zero if it is out of bounds
+func (p *planes) PointAt(x, y int) Point {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return Point{}
+	}
+	i := p.PixOffset(x, y)
+	return Point{float64(p.Pix[0][i]), float64(p.Pix[1][i]), float64(p.Pix[2][i])}
+}
+
+// Sets the components of the pixel at (x, y), pixels out of bounds are ignored
+func (p *planes) SetPoint(x, y int, pt Point) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	p.Pix[0][i], p.Pix[1][i], p.Pix[2][i] = float32(pt[0]), float32(pt[1]), float32(pt[2])
+}
+
+// Image whose pixels are stored as LAB colours
+type LABImage struct {
+	planes
+}
+
+// Returns a new LAB image with the given bounds
+func NewLABImage(r image.Rectangle) *LABImage {
+	return &LABImage{newPlanes(r)}
+}
+
+// Converts every pixel of the image into a LAB image
+func ToLABImage(img image.Image) *LABImage {
+	if p, ok := img.(*LABImage); ok {
+		return p
+	}
+
+	p := NewLABImage(img.Bounds())
+	p.fill(img, labPoint)
+	return p
+}
+
+func labPoint(rgb *RGB) Point {
+	lab := rgb.LAB()
+	return Point{lab.L, lab.A, lab.B}
+}
+
+func (p *LABImage) ColorModel() color.Model {
+	return LABModel
+}
+
+func (p *LABImage) At(x, y int) color.Color {
+	return p.LABAt(x, y)
+}
+
+// Returns the LAB colour of the pixel at (x, y), or black if it is out of bounds
+func (p *LABImage) LABAt(x, y int) LAB {
+	pt := p.PointAt(x, y)
+	return LAB{pt[0], pt[1], pt[2]}
+}
+
+func (p *LABImage) Set(x, y int, c color.Color) {
+	p.SetLAB(x, y, LABModel.Convert(c).(LAB))
+}
+
+// Sets the pixel at (x, y) to the LAB colour, pixels out of bounds are ignored
+func (p *LABImage) SetLAB(x, y int, c LAB) {
+	p.SetPoint(x, y, Point{c.L, c.A, c.B})
+}
+
+func (p *LABImage) Colour(pt Point) color.Color {
+	return LAB{pt[0], pt[1], pt[2]}
+}
+
+// Image whose pixels are stored as OKLab colours
+type OKLabImage struct {
+	planes
+}
+
+// Returns a new OKLab image with the given bounds
+func NewOKLabImage(r image.Rectangle) *OKLabImage {
+	return &OKLabImage{newPlanes(r)}
+}
+
+// Converts every pixel of the image into an OKLab image
+func ToOKLabImage(img image.Image) *OKLabImage {
+	if p, ok := img.(*OKLabImage); ok {
+		return p
+	}
+
+	p := NewOKLabImage(img.Bounds())
+	p.fill(img, oklabPoint)
+	return p
+}
+
+func oklabPoint(rgb *RGB) Point {
+	lab := rgb.OKLab()
+	return Point{lab.L, lab.A, lab.B}
+}
+
+func (p *OKLabImage) ColorModel() color.Model {
+	return OKLabModel
+}
+
+func (p *OKLabImage) At(x, y int) color.Color {
+	return p.Colour(p.PointAt(x, y))
+}
+
+func (p *OKLabImage) Set(x, y int, c color.Color) {
+	lab := OKLabModel.Convert(c).(OKLab)
+	p.SetPoint(x, y, Point{lab.L, lab.A, lab.B})
+}
+
+func (p *OKLabImage) Colour(pt Point) color.Color {
+	return OKLab{pt[0], pt[1], pt[2]}
+}
+
+// Image whose pixels are stored as linear RGB colours
+type LinearRGBImage struct {
+	planes
+}
+
+// Returns a new linear RGB image with the given bounds
+func NewLinearRGBImage(r image.Rectangle) *LinearRGBImage {
+	return &LinearRGBImage{newPlanes(r)}
+}
+
+// Converts every pixel of the image into a linear RGB image
+func ToLinearRGBImage(img image.Image) *LinearRGBImage {
+	if p, ok := img.(*LinearRGBImage); ok {
+		return p
+	}
+
+	p := NewLinearRGBImage(img.Bounds())
+	p.fill(img, linearRGBPoint)
+	return p
+}
+
+func linearRGBPoint(rgb *RGB) Point {
+	lin := rgb.LinearRGB()
+	return Point{lin.R, lin.G, lin.B}
+}
+
+func (p *LinearRGBImage) ColorModel() color.Model {
+	return LinearRGBModel
+}
+
+func (p *LinearRGBImage) At(x, y int) color.Color {
+	return p.Colour(p.PointAt(x, y))
+}
+
+func (p *LinearRGBImage) Set(x, y int, c color.Color) {
+	lin := LinearRGBModel.Convert(c).(LinearRGB)
+	p.SetPoint(x, y, Point{lin.R, lin.G, lin.B})
+}
+
+func (p *LinearRGBImage) Colour(pt Point) color.Color {
+	return LinearRGB{pt[0], pt[1], pt[2]}
+}
@@ -1,10 +1,31 @@
 package colours
 
+import (
+	"image/color"
+	"math"
+)
+
 // RGB Data, range 0-255
 type RGB struct {
 	R, G, B float64
 }
 
+// Model which converts colours to RGB
+var RGBModel = color.ModelFunc(rgbModel)
+
+func rgbModel(c color.Color) color.Color {
+	return *toRGB(c)
+}
+
+// Implements color.Color, the colour is clamped to the range [0, 255] and is fully opaque
+func (rgb RGB) RGBA() (r, g, b, a uint32) {
+	r = uint32(Clamp(rgb.R, 0, 255)*257 + 0.5)
+	g = uint32(Clamp(rgb.G, 0, 255)*257 + 0.5)
+	b = uint32(Clamp(rgb.B, 0, 255)*257 + 0.5)
+
+	return r, g, b, 0xffff
+}
+
 // Scales RGB colours in the range [0, 1]
 func (rgb *RGB) scale() (r, g, b float64) {
 	r = rgb.R / 255
@@ -14,9 +35,11 @@ func (rgb *RGB) scale() (r, g, b float64) {
 	return r, g, b
 }
 
-// Converts an RGB colour to the XYZ colour space
+// Converts an RGB colour to the XYZ colour space, the sRGB
+// transfer curve is removed before the sRGB matrix is applied
 func (rgb *RGB) XYZ() *XYZ {
 	r, g, b := rgb.scale()
+	r, g, b = linearise(r), linearise(g), linearise(b)
 
 	x := 0.4124564*r + 0.3575761*g + 0.1804375*b
 	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
@@ -29,3 +52,41 @@ func (rgb *RGB) XYZ() *XYZ {
 func (rgb *RGB) LAB() *LAB {
 	return rgb.XYZ().LAB()
 }
+
+// Converts an RGB colour to the OKLab colour space
+func (rgb *RGB) OKLab() *OKLab {
+	// OKLab is defined on linear sRGB
+	r, g, b := rgb.scale()
+	r, g, b = linearise(r), linearise(g), linearise(b)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return &OKLab{
+		0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// Converts an RGB colour to the OKLCh colour space
+func (rgb *RGB) OKLCh() *OKLCh {
+	return rgb.OKLab().OKLCh()
+}
+
+// Removes the sRGB transfer curve from a channel in the range [0, 1]
+func linearise(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// Applies the sRGB transfer curve to a linear channel in the range [0, 1]
+func compand(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
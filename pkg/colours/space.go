@@ -0,0 +1,211 @@
+package colours
+
+import "math"
+
+// Chromaticity coordinates x and y of a colour in the CIE 1931 xyY colour space
+type Chromaticity struct {
+	X, Y float64
+}
+
+// Returns the XYZ colour with the chromaticity whose luminance is one
+func (c Chromaticity) XYZ() *XYZ {
+	return &XYZ{c.X / c.Y, 1, (1 - c.X - c.Y) / c.Y}
+}
+
+// Standard illuminants
+var (
+	D50 = Chromaticity{0.3457, 0.3585}
+	D65 = Chromaticity{0.3127, 0.3290}
+)
+
+// Transfer function of an RGB working space, Decode converts an encoded channel in the
+// range [0, 1] to linear light and Encode converts a linear channel back
+type Transfer struct {
+	Decode func(v float64) float64
+	Encode func(v float64) float64
+}
+
+// Common transfer functions
+var (
+	SRGBTransfer    = Transfer{linearise, compand}
+	LinearTransfer  = Transfer{func(v float64) float64 { return v }, func(v float64) float64 { return v }}
+	Rec2020Transfer = Transfer{rec2020Decode, rec2020Encode}
+)
+
+// Returns a transfer function which is a pure power curve, e.g. 2.2 for Adobe RGB
+func GammaTransfer(gamma float64) Transfer {
+	return Transfer{
+		func(v float64) float64 { return math.Pow(v, gamma) },
+		func(v float64) float64 { return math.Pow(v, 1/gamma) },
+	}
+}
+
+// Constants of the Rec. 2020 transfer function
+const (
+	rec2020Alpha = 1.09929682680944
+	rec2020Beta  = 0.018053968510807
+)
+
+func rec2020Decode(v float64) float64 {
+	if v < 4.5*rec2020Beta {
+		return v / 4.5
+	}
+	return math.Pow((v+rec2020Alpha-1)/rec2020Alpha, 1/0.45)
+}
+
+func rec2020Encode(v float64) float64 {
+	if v < rec2020Beta {
+		return 4.5 * v
+	}
+	return rec2020Alpha*math.Pow(v, 0.45) - (rec2020Alpha - 1)
+}
+
+// RGB working space, defined by the chromaticities of its primaries and white point and by
+// its transfer function. RGB colours are in the range 0-255 whichever space they're in
+type WorkingSpace struct {
+	Red, Green, Blue Chromaticity
+	White            Chromaticity
+	Transfer         Transfer
+
+	toXYZ, fromXYZ matrix // Linear RGB to XYZ relative to D65 and back
+}
+
+// Common working spaces
+var (
+	SRGB       = NewWorkingSpace(Chromaticity{0.64, 0.33}, Chromaticity{0.30, 0.60}, Chromaticity{0.15, 0.06}, D65, SRGBTransfer)
+	LinearSRGB = NewWorkingSpace(Chromaticity{0.64, 0.33}, Chromaticity{0.30, 0.60}, Chromaticity{0.15, 0.06}, D65, LinearTransfer)
+	DisplayP3  = NewWorkingSpace(Chromaticity{0.680, 0.320}, Chromaticity{0.265, 0.690}, Chromaticity{0.150, 0.060}, D65, SRGBTransfer)
+	Rec2020    = NewWorkingSpace(Chromaticity{0.708, 0.292}, Chromaticity{0.170, 0.797}, Chromaticity{0.131, 0.046}, D65, Rec2020Transfer)
+)
+
+// Creates a working space from its primaries, white point and transfer function. Colours are
+// adapted to D65 when converted to XYZ, which is the white point the rest of this package uses
+func NewWorkingSpace(red, green, blue, white Chromaticity, transfer Transfer) *WorkingSpace {
+	// The columns are the XYZ values of each primary, scaled so that they add up to the white point
+	r, g, b := red.XYZ(), green.XYZ(), blue.XYZ()
+	primaries := matrix{
+		{r.X, g.X, b.X},
+		{r.Y, g.Y, b.Y},
+		{r.Z, g.Z, b.Z},
+	}
+	s := primaries.inverse().apply(white.XYZ())
+
+	var toXYZ matrix
+	for i := 0; i < 3; i++ {
+		toXYZ[i] = [3]float64{primaries[i][0] * s.X, primaries[i][1] * s.Y, primaries[i][2] * s.Z}
+	}
+	toXYZ = bradford(white, D65).mul(toXYZ)
+
+	return &WorkingSpace{
+		Red:      red,
+		Green:    green,
+		Blue:     blue,
+		White:    white,
+		Transfer: transfer,
+		toXYZ:    toXYZ,
+		fromXYZ:  toXYZ.inverse(),
+	}
+}
+
+// Converts an RGB colour in the working space to XYZ relative to D65
+func (ws *WorkingSpace) XYZ(rgb *RGB) *XYZ {
+	r, g, b := rgb.scale()
+	return ws.toXYZ.apply(&XYZ{ws.Transfer.Decode(r), ws.Transfer.Decode(g), ws.Transfer.Decode(b)})
+}
+
+// Converts an XYZ colour relative to D65 to an RGB colour in the working
+// space, colours outside of the working space's gamut are clamped
+func (ws *WorkingSpace) RGB(xyz *XYZ) *RGB {
+	lin := ws.fromXYZ.apply(xyz)
+	return &RGB{
+		ws.Transfer.Encode(Clamp(lin.X, 0, 1)) * 255,
+		ws.Transfer.Encode(Clamp(lin.Y, 0, 1)) * 255,
+		ws.Transfer.Encode(Clamp(lin.Z, 0, 1)) * 255,
+	}
+}
+
+// Converts an RGB colour in the working space to another working space,
+// colours outside of the other space's gamut are clamped
+func (ws *WorkingSpace) Convert(rgb *RGB, to *WorkingSpace) *RGB {
+	return to.RGB(ws.XYZ(rgb))
+}
+
+// Adapts an XYZ colour from one white point to another using the Bradford transform
+func (xyz *XYZ) Adapt(from, to Chromaticity) *XYZ {
+	return bradford(from, to).apply(xyz)
+}
+
+// Bradford cone response matrix
+var bradfordMatrix = matrix{
+	{0.8951, 0.2664, -0.1614},
+	{-0.7502, 1.7135, 0.0367},
+	{0.0389, -0.0685, 1.0296},
+}
+
+// Returns the matrix which adapts XYZ colours from one white point to another
+func bradford(from, to Chromaticity) matrix {
+	if from == to {
+		return identity
+	}
+
+	src, dst := bradfordMatrix.apply(from.XYZ()), bradfordMatrix.apply(to.XYZ())
+	scale := matrix{
+		{dst.X / src.X, 0, 0},
+		{0, dst.Y / src.Y, 0},
+		{0, 0, dst.Z / src.Z},
+	}
+
+	return bradfordMatrix.inverse().mul(scale.mul(bradfordMatrix))
+}
+
+// 3x3 matrix used for colour space conversions
+type matrix [3][3]float64
+
+var identity = matrix{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+// Multiplies the vector by the matrix
+func (m matrix) apply(v *XYZ) *XYZ {
+	return &XYZ{
+		m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// Returns the product of both matrices
+func (m matrix) mul(n matrix) matrix {
+	var p matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				p[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+	return p
+}
+
+// Returns the inverse of the matrix
+func (m matrix) inverse() matrix {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+
+	return matrix{
+		{
+			(m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det,
+			(m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det,
+			(m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det,
+		},
+		{
+			(m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det,
+			(m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det,
+			(m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det,
+		},
+		{
+			(m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det,
+			(m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det,
+			(m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det,
+		},
+	}
+}
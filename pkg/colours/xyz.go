@@ -1,17 +1,35 @@
 package colours
 
+import "image/color"
+
 // XYZ
 type XYZ struct {
 	X, Y, Z float64
 }
 
-// Converts an XYZ colour to the RGB colour space
+// Model which converts colours to XYZ
+var XYZModel = color.ModelFunc(xyzModel)
+
+func xyzModel(c color.Color) color.Color {
+	if _, ok := c.(XYZ); ok {
+		return c
+	}
+	return *toRGB(c).XYZ()
+}
+
+// Implements color.Color
+func (xyz XYZ) RGBA() (r, g, b, a uint32) {
+	return xyz.RGB().RGBA()
+}
+
+// Converts an XYZ colour to the RGB colour space, colours outside
+// of the sRGB gamut are clamped before the transfer curve is applied
 func (xyz *XYZ) RGB() *RGB {
-	r := Clamp((3.2404542*xyz.X+-1.5371385*xyz.Y+-0.4985314*xyz.Z)*255, 0, 255)
-	g := Clamp((-0.9692660*xyz.X+1.8760108*xyz.Y+0.0415560*xyz.Z)*255, 0, 255)
-	b := Clamp((0.0556434*xyz.X+-0.2040259*xyz.Y+1.0572252*xyz.Z)*255, 0, 255)
+	r := Clamp(3.2404542*xyz.X+-1.5371385*xyz.Y+-0.4985314*xyz.Z, 0, 1)
+	g := Clamp(-0.9692660*xyz.X+1.8760108*xyz.Y+0.0415560*xyz.Z, 0, 1)
+	b := Clamp(0.0556434*xyz.X+-0.2040259*xyz.Y+1.0572252*xyz.Z, 0, 1)
 
-	return &RGB{r, g, b}
+	return &RGB{compand(r) * 255, compand(g) * 255, compand(b) * 255}
 }
 
 // Converts an XYZ colour to the LAB colour space
@@ -23,9 +41,10 @@ func (xyz *XYZ) LAB() *LAB {
 	return &LAB{L, a, b}
 }
 
-// Standard Illuminati D65 used for XYZ conversion
+// Standard Illuminant D65 used for XYZ conversion, XYZ is
+// in the range [0, 1] so the white point is scaled to match
 const (
-	Xn float64 = 95.0489
-	Yn float64 = 100
-	Zn float64 = 109.8840
+	Xn float64 = 0.950489
+	Yn float64 = 1
+	Zn float64 = 1.08884
 )
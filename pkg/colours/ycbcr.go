@@ -0,0 +1,41 @@
+package colours
+
+import "image/color"
+
+// YCbCr Colour using the full range JPEG (BT.601) definition, all components are in the range [0, 255]
+type YCbCr struct {
+	Y, Cb, Cr float64
+}
+
+// Model which converts colours to YCbCr
+var YCbCrModel = color.ModelFunc(ycbcrModel)
+
+func ycbcrModel(c color.Color) color.Color {
+	if _, ok := c.(YCbCr); ok {
+		return c
+	}
+	return *toRGB(c).YCbCr()
+}
+
+// Converts an RGB colour to YCbCr
+func (rgb *RGB) YCbCr() *YCbCr {
+	y := 0.299*rgb.R + 0.587*rgb.G + 0.114*rgb.B
+	cb := 128 - 0.168736*rgb.R - 0.331264*rgb.G + 0.5*rgb.B
+	cr := 128 + 0.5*rgb.R - 0.418688*rgb.G - 0.081312*rgb.B
+
+	return &YCbCr{y, cb, cr}
+}
+
+// Converts a YCbCr colour to RGB, colours outside of the RGB gamut are clamped
+func (ycbcr *YCbCr) RGB() *RGB {
+	r := Clamp(ycbcr.Y+1.402*(ycbcr.Cr-128), 0, 255)
+	g := Clamp(ycbcr.Y-0.344136*(ycbcr.Cb-128)-0.714136*(ycbcr.Cr-128), 0, 255)
+	b := Clamp(ycbcr.Y+1.772*(ycbcr.Cb-128), 0, 255)
+
+	return &RGB{r, g, b}
+}
+
+// Implements color.Color
+func (ycbcr YCbCr) RGBA() (r, g, b, a uint32) {
+	return ycbcr.RGB().RGBA()
+}
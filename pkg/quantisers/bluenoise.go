@@ -0,0 +1,174 @@
+package quantisers
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Size of the built in blue noise texture
+const blueNoiseSize = 64
+
+var (
+	blueNoiseOnce    sync.Once
+	blueNoiseTexture ThresholdMap
+)
+
+// Returns the built in 64x64 blue noise texture, it's generated the first time it's needed
+func blueNoise() ThresholdMap {
+	blueNoiseOnce.Do(func() {
+		blueNoiseTexture = VoidAndCluster(blueNoiseSize, 1)
+	})
+	return blueNoiseTexture
+}
+
+// Generates a size x size blue noise threshold matrix using Ulichney's void-and-cluster
+// algorithm, each entry is its rank from 0 to size*size-1 like the Bayer matrices. The
+// seed picks the random starting pattern so the same seed always gives the same matrix
+func VoidAndCluster(size int, seed int64) ThresholdMap {
+	if size < 1 {
+		return nil
+	}
+	n := size * size
+	vc := newVoidCluster(size)
+
+	// Random starting pattern where about a tenth of the pixels are set
+	rng := rand.New(rand.NewSource(seed))
+	ones := n / 10
+	if ones < 1 {
+		ones = 1
+	}
+	for _, i := range rng.Perm(n)[:ones] {
+		vc.toggle(i)
+	}
+
+	// Spread the pattern out by moving the pixel in the tightest cluster to
+	// the largest void, until it would go straight back to where it was
+	for {
+		cluster := vc.tightestCluster()
+		vc.toggle(cluster)
+		void := vc.largestVoid()
+		if void == cluster {
+			vc.toggle(cluster)
+			break
+		}
+		vc.toggle(void)
+	}
+
+	ranks := make([]int, n)
+	prototype := vc.copy()
+
+	// Ranks below the starting pattern are given by removing clusters
+	for rank := ones - 1; rank >= 0; rank-- {
+		cluster := vc.tightestCluster()
+		vc.toggle(cluster)
+		ranks[cluster] = rank
+	}
+
+	// Ranks above it are given by filling voids, once more than half of the pixels
+	// are set this is the same as removing the tightest clusters of unset pixels
+	vc = prototype
+	for rank := ones; rank < n; rank++ {
+		void := vc.largestVoid()
+		vc.toggle(void)
+		ranks[void] = rank
+	}
+
+	matrix := make(ThresholdMap, size)
+	for y := range matrix {
+		matrix[y] = make([]float64, size)
+		for x := range matrix[y] {
+			matrix[y][x] = float64(ranks[y*size+x])
+		}
+	}
+
+	return matrix
+}
+
+// Binary pattern along with the energy of each pixel, which is the sum
+// of a gaussian centred on every set pixel, wrapping around the edges
+type voidCluster struct {
+	size     int
+	pattern  []bool
+	energy   []float64
+	gaussian []float64 // Gaussian by the horizontal and vertical distance between pixels
+}
+
+func newVoidCluster(size int) *voidCluster {
+	const sigma = 1.5
+
+	gaussian := make([]float64, size*size)
+	for dy := 0; dy < size; dy++ {
+		for dx := 0; dx < size; dx++ {
+			// Distances wrap around so the texture tiles seamlessly
+			x, y := float64(minInt(dx, size-dx)), float64(minInt(dy, size-dy))
+			gaussian[dy*size+dx] = math.Exp(-(x*x + y*y) / (2 * sigma * sigma))
+		}
+	}
+
+	return &voidCluster{
+		size:     size,
+		pattern:  make([]bool, size*size),
+		energy:   make([]float64, size*size),
+		gaussian: gaussian,
+	}
+}
+
+func (vc *voidCluster) copy() *voidCluster {
+	c := &voidCluster{
+		size:     vc.size,
+		pattern:  make([]bool, len(vc.pattern)),
+		energy:   make([]float64, len(vc.energy)),
+		gaussian: vc.gaussian,
+	}
+	copy(c.pattern, vc.pattern)
+	copy(c.energy, vc.energy)
+	return c
+}
+
+// Sets or unsets the pixel and updates the energy of every pixel
+func (vc *voidCluster) toggle(i int) {
+	sign := 1.0
+	if vc.pattern[i] {
+		sign = -1
+	}
+	vc.pattern[i] = !vc.pattern[i]
+
+	px, py := i%vc.size, i/vc.size
+	for y := 0; y < vc.size; y++ {
+		dy := y - py
+		if dy < 0 {
+			dy += vc.size
+		}
+		row := vc.gaussian[dy*vc.size : (dy+1)*vc.size]
+		for x := 0; x < vc.size; x++ {
+			dx := x - px
+			if dx < 0 {
+				dx += vc.size
+			}
+			vc.energy[y*vc.size+x] += sign * row[dx]
+		}
+	}
+}
+
+// Returns the set pixel with the most energy
+func (vc *voidCluster) tightestCluster() int {
+	best, energy := -1, math.Inf(-1)
+	for i, set := range vc.pattern {
+		if set && vc.energy[i] > energy {
+			best, energy = i, vc.energy[i]
+		}
+	}
+	return best
+}
+
+// Returns the unset pixel with the least energy
+func (vc *voidCluster) largestVoid() int {
+	best, energy := -1, math.Inf(1)
+	for i, set := range vc.pattern {
+		if !set && vc.energy[i] < energy {
+			best, energy = i, vc.energy[i]
+		}
+	}
+	return best
+}
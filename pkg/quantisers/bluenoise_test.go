@@ -0,0 +1,113 @@
+package quantisers
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestVoidAndCluster(t *testing.T) {
+	for _, size := range []int{1, 2, 5, 16, 64} {
+		m := VoidAndCluster(size, 1)
+		if len(m) != size || len(m[0]) != size || !ranked(m) {
+			t.Errorf("%d: doesn't rank every pixel exactly once", size)
+		}
+	}
+	for _, size := range []int{-1, 0} {
+		if m := VoidAndCluster(size, 1); m != nil {
+			t.Errorf("%d: got %v, want nil", size, m)
+		}
+	}
+
+	// The seed picks the starting pattern
+	if !reflect.DeepEqual(VoidAndCluster(16, 7), VoidAndCluster(16, 7)) {
+		t.Error("the same seed gave different textures")
+	}
+	if reflect.DeepEqual(VoidAndCluster(16, 7), VoidAndCluster(16, 8)) {
+		t.Error("different seeds gave the same texture")
+	}
+	if !reflect.DeepEqual(blueNoise(), VoidAndCluster(blueNoiseSize, 1)) {
+		t.Error("the built in texture isn't the 64x64 texture with a seed of one")
+	}
+}
+
+// Returns the smallest distance between the pixels of the texture ranked below the
+// level, the distance wraps around the edges since the texture is tiled
+func minSpacing(m ThresholdMap, level float64) int {
+	size := len(m)
+	var pixels [][2]int
+	for y := range m {
+		for x := range m[y] {
+			if m[y][x] < level {
+				pixels = append(pixels, [2]int{x, y})
+			}
+		}
+	}
+
+	spacing := size * size
+	for i := range pixels {
+		for j := i + 1; j < len(pixels); j++ {
+			dx, dy := pixels[i][0]-pixels[j][0], pixels[i][1]-pixels[j][1]
+			if dx < 0 {
+				dx = -dx
+			}
+			if dy < 0 {
+				dy = -dy
+			}
+			dx, dy = minInt(dx, size-dx), minInt(dy, size-dy)
+			spacing = minInt(spacing, dx*dx+dy*dy)
+		}
+	}
+	return spacing
+}
+
+func TestVoidAndClusterSpacing(t *testing.T) {
+	// The lowest ranks are spread out evenly, while pixels picked at random would touch
+	m := VoidAndCluster(32, 1)
+	tests := []struct {
+		level   float64
+		spacing int
+	}{
+		{32, 16},
+		{64, 8},
+		{102, 4},
+	}
+
+	for _, tt := range tests {
+		if got := minSpacing(m, tt.level); got < tt.spacing {
+			t.Errorf("%v: got a squared spacing of %d, want at least %d", tt.level, got, tt.spacing)
+		}
+	}
+}
+
+func TestBlueNoise(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	palette := color.Palette{color.Gray{}, color.Gray{Y: 255}}
+
+	// About half of a mid grey is dithered to white
+	for _, texture := range []ThresholdMap{nil, VoidAndCluster(16, 3)} {
+		got, err := ImageFromPaletteWithOpts(img, palette, Options{Dither: BlueNoise, Texture: texture})
+		if err != nil {
+			t.Fatal(err)
+		}
+		white := 0
+		for i := 0; i < len(got.(*image.RGBA).Pix); i += 4 {
+			if got.(*image.RGBA).Pix[i] == 255 {
+				white++
+			}
+		}
+		if white < 64*64*4/10 || white > 64*64*6/10 {
+			t.Errorf("%d of %d pixels are white", white, 64*64)
+		}
+	}
+
+	for _, texture := range []ThresholdMap{{}, {{}}, {{0, 1}, {2}}} {
+		if _, err := ImageFromPaletteWithOpts(img, palette, Options{Dither: BlueNoise, Texture: texture}); err == nil {
+			t.Errorf("%v: expected an error for an invalid texture", texture)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package quantisers
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image"
+	"image/color"
+)
+
+// Kernel used for error diffusion. The first row of the matrix is the current row and the
+// current pixel is at column X of it, each entry is the share of the pixel's error which is
+// given to that neighbour once divided by the divisor. Entries at or before the current
+// pixel in the first row must be zero because those pixels have already been processed
+type Kernel struct {
+	Matrix  [][]float64
+	X       int
+	Divisor float64 // If zero then the sum of the matrix is used
+}
+
+// Error diffusion kernels - https://tannerhelland.com/2012/12/28/dithering-eleven-algorithms-source-code.html
+var (
+	FloydSteinbergKernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 7},
+			{3, 5, 1},
+		},
+		X: 1, Divisor: 16,
+	}
+	JarvisJudiceNinkeKernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 0, 7, 5},
+			{3, 5, 7, 5, 3},
+			{1, 3, 5, 3, 1},
+		},
+		X: 2, Divisor: 48,
+	}
+	StuckiKernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 0, 8, 4},
+			{2, 4, 8, 4, 2},
+			{1, 2, 4, 2, 1},
+		},
+		X: 2, Divisor: 42,
+	}
+	BurkesKernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 0, 8, 4},
+			{2, 4, 8, 4, 2},
+		},
+		X: 2, Divisor: 32,
+	}
+	Sierra3Kernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 0, 5, 3},
+			{2, 4, 5, 4, 2},
+			{0, 2, 3, 2, 0},
+		},
+		X: 2, Divisor: 32,
+	}
+	Sierra2Kernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 0, 4, 3},
+			{1, 2, 3, 2, 1},
+		},
+		X: 2, Divisor: 16,
+	}
+	SierraLiteKernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 2},
+			{1, 1, 0},
+		},
+		X: 1, Divisor: 4,
+	}
+	// Only diffuses 3/4 of the error which keeps more contrast
+	AtkinsonKernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 1, 1},
+			{1, 1, 1, 0},
+			{0, 1, 0, 0},
+		},
+		X: 1, Divisor: 8,
+	}
+	StevensonArceKernel = Kernel{
+		Matrix: [][]float64{
+			{0, 0, 0, 0, 0, 32, 0},
+			{12, 0, 26, 0, 30, 0, 16},
+			{0, 12, 0, 26, 0, 12, 0},
+			{5, 0, 12, 0, 12, 0, 5},
+		},
+		X: 3, Divisor: 200,
+	}
+)
+
+// Returns the divisor of the kernel
+func (k Kernel) divisor() float64 {
+	if k.Divisor != 0 {
+		return k.Divisor
+	}
+
+	var sum float64
+	for _, row := range k.Matrix {
+		for _, w := range row {
+			sum += w
+		}
+	}
+	return sum
+}
+
+// Returns whether the current pixel is in the first row, no error is given to pixels which
+// have already been processed and the divisor isn't zero
+func (k Kernel) valid() bool {
+	if len(k.Matrix) == 0 || k.X < 0 || k.X >= len(k.Matrix[0]) || k.divisor() == 0 {
+		return false
+	}
+	for _, w := range k.Matrix[0][:k.X+1] {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Neighbour which receives a share of a pixel's error
+type tap struct {
+	dx, dy int
+	w      float32
+}
+
+// Returns the neighbours which receive error and the furthest horizontal distance of
+// them, the error is scaled by the strength so that it can be partially diffused
+func (k Kernel) taps(strength float64) ([]tap, int) {
+	divisor := k.divisor()
+
+	var taps []tap
+	var margin int
+	for dy, row := range k.Matrix {
+		for i, w := range row {
+			dx := i - k.X
+			if w == 0 || (dy == 0 && dx <= 0) {
+				continue
+			}
+			taps = append(taps, tap{dx, dy, float32(w * strength / divisor)})
+			if dx < 0 {
+				dx = -dx
+			}
+			if dx > margin {
+				margin = dx
+			}
+		}
+	}
+
+	return taps, margin
+}
+
+// Error diffusion using the kernel, if serpentine then every other row is processed right to left
+// with the kernel mirrored - https://en.wikipedia.org/wiki/Error_diffusion. The error is carried in
+// float buffers of the rows the kernel covers instead of in the image, so it isn't rounded away, and
+// each pixel keeps its alpha with the palette colour's alpha ignored. Values are alpha-premultiplied
+// like the pixels of image.RGBA and are clamped to the valid range before the error is measured,
+// error which no colour can represent would otherwise build up and streak across the image
+func errorDiffusion(cimg *image.RGBA, model color.Model, k Kernel, serpentine bool, strength float64) *image.RGBA {
+	taps, margin := k.taps(strength)
+	bounds := cimg.Bounds()
+	width := bounds.Dx()
+
+	// Ring of error rows, each has room for the kernel to overhang both sides of the image
+	rows := len(k.Matrix)
+	if rows < 1 {
+		rows = 1
+	}
+	errs := make([][]float32, rows)
+	for i := range errs {
+		errs[i] = make([]float32, 3*(width+2*margin))
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		cur := errs[(y-bounds.Min.Y)%rows]
+
+		start, end, direction := bounds.Min.X, bounds.Max.X, 1
+		if serpentine && (y-bounds.Min.Y)%2 == 1 {
+			start, end, direction = bounds.Max.X-1, bounds.Min.X-1, -1
+		}
+
+		for x := start; x != end; x += direction {
+			i := cimg.PixOffset(x, y)
+			e := 3 * (x - bounds.Min.X + margin)
+
+			// Premultiplied colours can't be brighter than their alpha
+			a := cimg.Pix[i+3]
+			r := clampToAlpha(float32(cimg.Pix[i])+cur[e], a)
+			g := clampToAlpha(float32(cimg.Pix[i+1])+cur[e+1], a)
+			b := clampToAlpha(float32(cimg.Pix[i+2])+cur[e+2], a)
+
+			nr, ng, nb, na := model.Convert(color.RGBA{
+				R: uint8(r + 0.5),
+				G: uint8(g + 0.5),
+				B: uint8(b + 0.5),
+				A: a,
+			}).RGBA()
+			pr, pg, pb := withAlpha(nr, na, a), withAlpha(ng, na, a), withAlpha(nb, na, a)
+			cimg.Pix[i], cimg.Pix[i+1], cimg.Pix[i+2] = pr, pg, pb
+
+			rErr, gErr, bErr := r-float32(pr), g-float32(pg), b-float32(pb)
+			for _, t := range taps {
+				row := errs[(y-bounds.Min.Y+t.dy)%rows]
+				j := e + 3*t.dx*direction
+				row[j] += rErr * t.w
+				row[j+1] += gErr * t.w
+				row[j+2] += bErr * t.w
+			}
+		}
+
+		// The row is reused for the row after the last one the kernel reaches
+		for j := range cur {
+			cur[j] = 0
+		}
+	}
+
+	return cimg
+}
+
+// Error diffusion of a planar image in the colour space of the remapper's metric, the error is
+// measured between points so none of the pixels are converted. Values are clamped to the range
+// of the image and palette points for the same reason errorDiffusion clamps them, see pointGrid
+func errorDiffusionPoints(points colours.PointImage, r *Remapper, k Kernel, serpentine bool, strength float64) *image.RGBA {
+	taps, margin := k.taps(strength)
+	grid := newPointGrid(points, r.points)
+	bounds := points.Bounds()
+	width := bounds.Dx()
+	img := image.NewRGBA(bounds)
+
+	rows := len(k.Matrix)
+	if rows < 1 {
+		rows = 1
+	}
+	errs := make([][]float32, rows)
+	for i := range errs {
+		errs[i] = make([]float32, 3*(width+2*margin))
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		cur := errs[(y-bounds.Min.Y)%rows]
+
+		start, end, direction := bounds.Min.X, bounds.Max.X, 1
+		if serpentine && (y-bounds.Min.Y)%2 == 1 {
+			start, end, direction = bounds.Max.X-1, bounds.Min.X-1, -1
+		}
+
+		for x := start; x != end; x += direction {
+			e := 3 * (x - bounds.Min.X + margin)
+
+			p := points.PointAt(x, y)
+			for c := range p {
+				p[c] += float64(cur[e+c])
+			}
+			p = grid.snap(p)
+			i := r.IndexPoint(p)
+			img.Set(x, y, r.palette[i])
+
+			q := r.points[i]
+			for _, t := range taps {
+				row := errs[(y-bounds.Min.Y+t.dy)%rows]
+				j := e + 3*t.dx*direction
+				for c := range p {
+					row[j+c] += float32(p[c]-q[c]) * t.w
+				}
+			}
+		}
+
+		for j := range cur {
+			cur[j] = 0
+		}
+	}
+
+	return img
+}
+
+// Returns the 8-bit value of a channel of a colour premultiplied by the alpha a instead
+// of by its own alpha, the channel and the colour's alpha are 16-bit like those from RGBA
+func withAlpha(v, ca uint32, a uint8) uint8 {
+	if ca == 0 {
+		return 0
+	}
+	return uint8((v*uint32(a) + ca/2) / ca)
+}
+
+// Clamps the value to the range [0, a]
+func clampToAlpha(v float32, a uint8) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > float32(a) {
+		return float32(a)
+	}
+	return v
+}
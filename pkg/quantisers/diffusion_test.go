@@ -0,0 +1,104 @@
+package quantisers
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestErrorDiffusionAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(8 * x), G: 100, B: 50, A: uint8(32 * y)})
+		}
+	}
+
+	for name, d := range ditherTypes {
+		if _, ok := ditherKernels[d]; !ok {
+			continue
+		}
+		got, err := ImageFromPalette(img, testPalette, d)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 32; x++ {
+				if _, _, _, a := got.At(x, y).RGBA(); a>>8 != uint32(32*y) {
+					t.Errorf("%s: pixel (%d, %d) has alpha %d, want %d", name, x, y, a>>8, 32*y)
+				}
+			}
+		}
+	}
+}
+
+func TestKernels(t *testing.T) {
+	for name, d := range ditherTypes {
+		k, ok := ditherKernels[d]
+		if !ok {
+			continue
+		}
+
+		// Atkinson only diffuses 3/4 of the error, every other kernel diffuses all of it
+		share := 1.0
+		if d == Atkinson {
+			share = 0.75
+		}
+
+		var sum float64
+		for dy, row := range k.Matrix {
+			for i, w := range row {
+				if dy == 0 && i <= k.X && w != 0 {
+					t.Errorf("%s: weight %v given to a processed pixel", name, w)
+				}
+				sum += w
+			}
+		}
+		if sum != share*k.Divisor {
+			t.Errorf("%s: weights sum to %v, want %v of the divisor %v", name, sum, share, k.Divisor)
+		}
+
+		var total float64
+		taps, _ := k.taps(1)
+		for _, tap := range taps {
+			total += float64(tap.w)
+		}
+		if math.Abs(total-share) > 1e-6 {
+			t.Errorf("%s: taps diffuse %v of the error, want %v", name, total, share)
+		}
+	}
+}
+
+func TestCustomKernel(t *testing.T) {
+	img := gradient()
+	want, _ := ImageFromPalette(img, testPalette, FloydSteinberg)
+	wantSerpentine, _ := ImageFromPalette(img, testPalette, FloydSteinbergSerpentine)
+
+	// Without a divisor the sum of the matrix is used
+	kernel := FloydSteinbergKernel
+	kernel.Divisor = 0
+	tests := []struct {
+		name string
+		opts Options
+		want image.Image
+	}{
+		{"custom", Options{Dither: CustomKernel, Kernel: &FloydSteinbergKernel}, want},
+		{"no divisor", Options{Dither: CustomKernel, Kernel: &kernel}, want},
+		{"serpentine", Options{Dither: CustomKernel, Kernel: &FloydSteinbergKernel, Serpentine: true}, wantSerpentine},
+		{"serpentine named", Options{Dither: FloydSteinberg, Serpentine: true}, wantSerpentine},
+	}
+
+	for _, tt := range tests {
+		got, err := ImageFromPaletteWithOpts(img, testPalette, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !equal(got, tt.want) {
+			t.Errorf("%s: image differs from Floyd-Steinberg", tt.name)
+		}
+	}
+	if equal(want, wantSerpentine) {
+		t.Error("serpentine and raster order give the same image")
+	}
+}
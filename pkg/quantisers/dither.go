@@ -7,8 +7,6 @@ import (
 	"math"
 )
 
-// TODO FLoydSteinberd Serpentine Traversal
-
 type DitherType int
 
 const (
@@ -18,8 +16,33 @@ const (
 	Bayer2x2
 	Bayer4x4
 	Bayer8x8
+	JarvisJudiceNinke
+	Stucki
+	Burkes
+	Sierra3
+	Sierra2
+	SierraLite
+	Atkinson
+	StevensonArce
+	CustomKernel       // Error diffusion using the kernel given in the options
+	BlueNoise          // Ordered dithering with a void-and-cluster blue noise texture
+	CustomThresholdMap // Ordered dithering using the threshold map given in the options
 )
 
+// Kernels of the error diffusion dither types
+var ditherKernels = map[DitherType]Kernel{
+	FloydSteinberg:           FloydSteinbergKernel,
+	FloydSteinbergSerpentine: FloydSteinbergKernel,
+	JarvisJudiceNinke:        JarvisJudiceNinkeKernel,
+	Stucki:                   StuckiKernel,
+	Burkes:                   BurkesKernel,
+	Sierra3:                  Sierra3Kernel,
+	Sierra2:                  Sierra2Kernel,
+	SierraLite:               SierraLiteKernel,
+	Atkinson:                 AtkinsonKernel,
+	StevensonArce:            StevensonArceKernel,
+}
+
 // No Dither
 func noDitherSingle(cimg *image.RGBA, c color.Palette) *image.RGBA {
 	bounds := cimg.Bounds()
@@ -42,90 +65,76 @@ func noDitherSingle(cimg *image.RGBA, c color.Palette) *image.RGBA {
 	return cimg
 }
 
-func noDitherMulti(cimg *image.RGBA, c color.Palette) *image.RGBA {
+func noDitherMulti(cimg *image.RGBA, model color.Model) *image.RGBA {
 	bounds := cimg.Bounds()
 	width, height := bounds.Max.X, bounds.Max.Y
 	for y := bounds.Min.Y; y < height; y++ {
 		for x := bounds.Min.X; x < width; x++ {
-			cimg.Set(x, y, c.Convert(cimg.At(x, y)))
+			cimg.Set(x, y, model.Convert(cimg.At(x, y)))
 		}
 	}
 
 	return cimg
 }
 
-// Floyd-steinberg dithering https://en.wikipedia.org/wiki/Floyd%E2%80%93Steinberg_dithering
-func floydSteinbergDither(cimg *image.RGBA, c color.Palette) *image.RGBA {
-	bounds := cimg.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	for y := bounds.Min.Y; y < height; y++ {
-		for x := bounds.Min.X; x < width; x++ {
-			floydSteinbergProcess(cimg, c, x, y, true)
+// Remaps the points of a planar image without converting its pixels
+func noDitherPoints(points colours.PointImage, r *Remapper) *image.RGBA {
+	bounds := points.Bounds()
+	img := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			img.Set(x, y, r.palette[r.IndexPoint(points.PointAt(x, y))])
 		}
 	}
 
-	return cimg
+	return img
 }
 
-func floydSteinbergSerpentineDither(cimg *image.RGBA, c color.Palette) *image.RGBA {
-	bounds := cimg.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	for y := bounds.Min.Y; y < height; y++ {
-		if y%2 == 0 {
-			for x := bounds.Min.X; x < width; x++ {
-				floydSteinbergProcess(cimg, c, x, y, true)
-			}
-		} else {
-			for x := width - 1; x >= 0; x-- {
-				floydSteinbergProcess(cimg, c, x, y, false)
-			}
-		}
-	}
-
-	return cimg
+// Grid with 256 steps across the range of each component of an image's points and the palette's
+// points. Dithered points are clamped to it so error can't build up and snapped to it like the
+// 8-bit channels of RGB pixels, so the remapper's cache is hit as often for points as for colours
+type pointGrid struct {
+	lo, hi, step colours.Point
 }
 
-// Forwards = left to right
-// Backwards = right to left
-func floydSteinbergProcess(cimg *image.RGBA, c color.Palette, x, y int, forwards bool) {
-	oldColour := cimg.At(x, y)
-	newColour := c.Convert(oldColour)
-	cimg.Set(x, y, newColour)
-	rErr, gErr, bErr := fsQuantisedErrors(newColour, oldColour)
-
-	if forwards {
-		cimg.Set(x+1, y, fsDiffuseErrors(x+1, y, cimg, rErr, gErr, bErr, 7.0/16))
-		cimg.Set(x-1, y+1, fsDiffuseErrors(x-1, y+1, cimg, rErr, gErr, bErr, 3.0/16))
-		cimg.Set(x, y+1, fsDiffuseErrors(x, y+1, cimg, rErr, gErr, bErr, 5.0/16))
-		cimg.Set(x+1, y+1, fsDiffuseErrors(x+1, y+1, cimg, rErr, gErr, bErr, 1.0/16))
-	} else {
-		cimg.Set(x-1, y, fsDiffuseErrors(x-1, y, cimg, rErr, gErr, bErr, 7.0/16))
-		cimg.Set(x+1, y+1, fsDiffuseErrors(x+1, y+1, cimg, rErr, gErr, bErr, 3.0/16))
-		cimg.Set(x, y+1, fsDiffuseErrors(x, y+1, cimg, rErr, gErr, bErr, 5.0/16))
-		cimg.Set(x-1, y+1, fsDiffuseErrors(x-1, y+1, cimg, rErr, gErr, bErr, 1.0/16))
+func newPointGrid(points colours.PointImage, palette []colours.Point) pointGrid {
+	g := pointGrid{
+		lo: colours.Point{math.Inf(1), math.Inf(1), math.Inf(1)},
+		hi: colours.Point{math.Inf(-1), math.Inf(-1), math.Inf(-1)},
 	}
-}
-
-func fsDiffuseErrors(x, y int, img *image.RGBA, rErr, gErr, bErr, mul float64) color.Color {
-	r, g, b, _ := img.At(x, y).RGBA()
-
-	return color.RGBA{
-		R: colours.ClampFloatToUint8(float64(r>>8) + rErr*mul),
-		G: colours.ClampFloatToUint8(float64(g>>8) + gErr*mul),
-		B: colours.ClampFloatToUint8(float64(b>>8) + bErr*mul),
-		A: 255,
+	extend := func(p colours.Point) {
+		for c := range p {
+			g.lo[c], g.hi[c] = math.Min(g.lo[c], p[c]), math.Max(g.hi[c], p[c])
+		}
 	}
-}
 
-func fsQuantisedErrors(c1, c2 color.Color) (float64, float64, float64) {
-	r1, g1, b1, _ := c1.RGBA()
-	r2, g2, b2, _ := c2.RGBA()
+	bounds := points.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			extend(points.PointAt(x, y))
+		}
+	}
+	for _, p := range palette {
+		extend(p)
+	}
+	for c := range g.step {
+		g.step[c] = (g.hi[c] - g.lo[c]) / 255
+	}
 
-	rErr := float64(r2>>8) - float64(r1>>8)
-	gErr := float64(g2>>8) - float64(g1>>8)
-	bErr := float64(b2>>8) - float64(b1>>8)
+	return g
+}
 
-	return rErr, gErr, bErr
+// Clamps the point to the grid and snaps it to the nearest step
+func (g pointGrid) snap(p colours.Point) colours.Point {
+	for c := range p {
+		if g.step[c] == 0 {
+			p[c] = g.lo[c]
+			continue
+		}
+		v := math.Min(math.Max(p[c], g.lo[c]), g.hi[c])
+		p[c] = g.lo[c] + math.Round((v-g.lo[c])/g.step[c])*g.step[c]
+	}
+	return p
 }
 
 // Bayer Dithering
@@ -149,52 +158,20 @@ func averageColourSpread(c color.Palette) float64 {
 	return math.Sqrt(dst) / total
 }
 
-var bayerMatrix2x2 = [][]float64{
-	{0, 2},
-	{3, 1},
-}
-
-var bayerMatrix4x4 = [][]float64{
-	{0, 8, 2, 10},
-	{12, 4, 14, 6},
-	{3, 11, 1, 9},
-	{15, 7, 13, 5},
-}
-
-var bayerMatrix8x8 = [][]float64{
-	{0, 32, 8, 40, 2, 34, 10, 42},
-	{48, 16, 56, 24, 50, 18, 58, 26},
-	{12, 44, 4, 36, 14, 46, 6, 38},
-	{60, 28, 52, 20, 62, 30, 54, 22},
-	{3, 35, 11, 43, 1, 33, 9, 41},
-	{51, 19, 59, 27, 49, 17, 57, 25},
-	{15, 47, 7, 39, 13, 45, 5, 37},
-	{63, 31, 55, 23, 61, 29, 53, 21},
-}
-
-func bayerDither2x2(cimg *image.RGBA, c color.Palette) *image.RGBA {
-	return bayerDitherWithOpts(cimg, c, bayerMatrix2x2)
-}
-
-func bayerDither4x4(cimg *image.RGBA, c color.Palette) *image.RGBA {
-	return bayerDitherWithOpts(cimg, c, bayerMatrix4x4)
-}
-
-func bayerDither8x8(cimg *image.RGBA, c color.Palette) *image.RGBA {
-	return bayerDitherWithOpts(cimg, c, bayerMatrix8x8)
-}
-
-func bayerDitherWithOpts(cimg *image.RGBA, c color.Palette, matrix [][]float64) *image.RGBA {
-	rowL := len(matrix[0])
-	mSize := colours.Sqr(float64(rowL))
-	spread := averageColourSpread(c)
+// Ordered dithering with any threshold map, the map is tiled over the image and
+// doesn't need to be square. Each pixel is offset by its level in the map before
+// being converted to the nearest palette colour
+func bayerDitherWithOpts(cimg *image.RGBA, c color.Palette, model color.Model, matrix ThresholdMap, strength float64) *image.RGBA {
+	rows, cols := len(matrix), len(matrix[0])
+	levels := matrix.levels()
+	spread := averageColourSpread(c) * strength
 	bounds := cimg.Bounds()
 	width, height := bounds.Max.X, bounds.Max.Y
 	for y := bounds.Min.Y; y < height; y++ {
 		for x := bounds.Min.X; x < width; x++ {
-			m := matrix[x%rowL][y%rowL]/mSize - 0.5
+			m := matrix[y%rows][x%cols]/levels - 0.5
 			r, g, b, _ := cimg.At(x, y).RGBA()
-			clr := c.Convert(color.RGBA{
+			clr := model.Convert(color.RGBA{
 				R: colours.ClampFloatToUint8(float64(r>>8) + spread*m),
 				G: colours.ClampFloatToUint8(float64(g>>8) + spread*m),
 				B: colours.ClampFloatToUint8(float64(b>>8) + spread*m),
@@ -206,3 +183,46 @@ func bayerDitherWithOpts(cimg *image.RGBA, c color.Palette, matrix [][]float64)
 
 	return cimg
 }
+
+// Ordered dithering of a planar image in the colour space of the remapper's metric. Each
+// point is offset along the grey axis of the space, which is scaled so that its largest
+// component is one like the offset given to each channel of RGB pixels, by the spread of
+// the palette points
+func orderedDitherPoints(points colours.PointImage, r *Remapper, matrix ThresholdMap, strength float64) *image.RGBA {
+	rows, cols := len(matrix), len(matrix[0])
+	levels := matrix.levels()
+	grid := newPointGrid(points, r.points)
+
+	black, white := r.metric.Convert(&colours.RGB{}), r.metric.Convert(&colours.RGB{R: 255, G: 255, B: 255})
+	var axis colours.Point
+	var scale float64
+	for c := range axis {
+		axis[c] = white[c] - black[c]
+		scale = math.Max(scale, math.Abs(axis[c]))
+	}
+
+	var dst float64
+	for i := range r.points {
+		for j := range r.points {
+			for c := range axis {
+				dst += colours.Sqr(r.points[i][c] - r.points[j][c])
+			}
+		}
+	}
+	spread := math.Sqrt(dst) / colours.Sqr(float64(len(r.points))) * strength
+
+	bounds := points.Bounds()
+	img := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			m := matrix[y%rows][x%cols]/levels - 0.5
+			p := points.PointAt(x, y)
+			for c := range p {
+				p[c] += spread * m * axis[c] / scale
+			}
+			img.Set(x, y, r.palette[r.IndexPoint(grid.snap(p))])
+		}
+	}
+
+	return img
+}
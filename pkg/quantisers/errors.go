@@ -0,0 +1,28 @@
+package quantisers
+
+import (
+	"errors"
+	"image"
+)
+
+// Errors returned by the quantisers, they can be matched with errors.Is
+var (
+	ErrUnknownQuantiser       = errors.New("no quantiser registered with that name")
+	ErrUnsupported            = errors.New("quantiser does not support this type of quantisation")
+	ErrInvalidPaletteSize     = errors.New("palette size must be at least 1")
+	ErrUnsupportedPaletteSize = errors.New("quantiser does not support this palette size")
+	ErrEmptyImage             = errors.New("image contains no pixels")
+	ErrEmptyPalette           = errors.New("colour palette must be specified")
+)
+
+// Checks an image and palette size can be quantised, quantisers should
+// call this before quantising so they return consistent errors
+func Validate(img image.Image, m int) error {
+	if m < 1 {
+		return ErrInvalidPaletteSize
+	}
+	if img == nil || img.Bounds().Empty() {
+		return ErrEmptyImage
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package kapur
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisers"
+	"image"
+	"image/color"
+	"math"
+)
+
+const xMax = 256
+
+// Returns one greyscale colour which best represents the threshold for splitting
+// the image into black and white using Kapur's maximum entropy method. This
+// works better than Otsu on images whose histogram has a single peak
+func QuantiseGreyscale(img image.Image) color.Palette {
+	histogram := quantisers.CreateGreyscaleHistogram(img)
+	threshold := calculateThreshold(histogram)
+	return color.Palette{color.Gray{threshold}}
+}
+
+// Calculates the threshold which maximises the sum of the entropies of the
+// black and white classes. If the image has one grey level then it is returned
+func calculateThreshold(hist quantisers.LinearHistogram) uint8 {
+	total := 0
+	for _, v := range hist {
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+
+	// Cumulative probability and p*ln(p) of each grey level
+	P := make([]float64, xMax)
+	E := make([]float64, xMax)
+	var p, e float64
+	for v := 0; v < xMax; v++ {
+		if hist[uint8(v)] > 0 {
+			pv := float64(hist[uint8(v)]) / float64(total)
+			p += pv
+			e += pv * math.Log(pv)
+		}
+		P[v], E[v] = p, e
+	}
+
+	var T uint8 = 0
+	var maxEntropy = math.Inf(-1)
+	for t := 0; t < xMax-1; t++ {
+		P0, P1 := P[t], 1-P[t]
+		if P0 <= 0 || P1 <= 1e-12 {
+			continue
+		}
+
+		H0 := math.Log(P0) - E[t]/P0
+		H1 := math.Log(P1) - (E[xMax-1]-E[t])/P1
+		if H0+H1 > maxEntropy {
+			maxEntropy = H0 + H1
+			T = uint8(t)
+		}
+	}
+
+	// Every pixel has the same grey level
+	if math.IsInf(maxEntropy, -1) {
+		for v := range hist {
+			T = v
+		}
+	}
+
+	return T
+}
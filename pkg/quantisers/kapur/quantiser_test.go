@@ -0,0 +1,41 @@
+package kapur
+
+import (
+	"errors"
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+var stripes = quantisetest.GreyStripes
+
+func TestQuantiseGreyscale(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		want color.Palette
+	}{
+		{"single colour", stripes(100), color.Palette{color.Gray{100}}},
+		{"two classes", stripes(0, 10, 20, 200, 210, 220), color.Palette{color.Gray{20}}},
+		{"unequal classes", stripes(0, 10, 20, 200), color.Palette{color.Gray{10}}},
+	}
+
+	for _, tt := range tests {
+		palette, err := Quantiser{}.QuantiseGreyscale(tt.img, 1)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(palette, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, palette, tt.want)
+		}
+	}
+}
+
+func TestUnsupportedPaletteSize(t *testing.T) {
+	if _, err := (Quantiser{}).QuantiseGreyscale(stripes(0, 255), 2); !errors.Is(err, quantisers.ErrUnsupportedPaletteSize) {
+		t.Errorf("got error %v, want %v", err, quantisers.ErrUnsupportedPaletteSize)
+	}
+}
@@ -0,0 +1,71 @@
+package kittler
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisers"
+	"image"
+	"image/color"
+	"math"
+)
+
+const xMax = 256
+
+// Returns one greyscale colour which best represents the threshold for splitting the
+// image into black and white using Kittler and Illingworth's minimum error method. This
+// works better than Otsu when the black and white classes differ a lot in size or spread
+func QuantiseGreyscale(img image.Image) color.Palette {
+	histogram := quantisers.CreateGreyscaleHistogram(img)
+	threshold := calculateThreshold(histogram)
+	return color.Palette{color.Gray{threshold}}
+}
+
+// Calculates the threshold which minimises the classification error when the black and
+// white classes are modelled as normal distributions. If no threshold gives two classes
+// with non zero variance then the mean grey level of the image is used
+func calculateThreshold(hist quantisers.LinearHistogram) uint8 {
+	// Cumulative number of pixels, sum and sum of squares of their grey levels
+	P := make([]float64, xMax)
+	S := make([]float64, xMax)
+	S2 := make([]float64, xMax)
+	var p, s, s2 float64
+	for v := 0; v < xMax; v++ {
+		n := float64(hist[uint8(v)])
+		p += n
+		s += n * float64(v)
+		s2 += n * float64(v*v)
+		P[v], S[v], S2[v] = p, s, s2
+	}
+	total := P[xMax-1]
+	if total == 0 {
+		return 0
+	}
+
+	var T uint8 = 0
+	var minError = math.Inf(1)
+	for t := 0; t < xMax-1; t++ {
+		n0, n1 := P[t], total-P[t]
+		if n0 == 0 || n1 == 0 {
+			continue
+		}
+
+		mean0 := S[t] / n0
+		mean1 := (S[xMax-1] - S[t]) / n1
+		var0 := S2[t]/n0 - mean0*mean0
+		var1 := (S2[xMax-1]-S2[t])/n1 - mean1*mean1
+		if var0 <= 1e-9 || var1 <= 1e-9 {
+			continue
+		}
+
+		P0, P1 := n0/total, n1/total
+		J := 1 + P0*math.Log(var0) + P1*math.Log(var1) - 2*(P0*math.Log(P0)+P1*math.Log(P1))
+		if J < minError {
+			minError = J
+			T = uint8(t)
+		}
+	}
+
+	if math.IsInf(minError, 1) {
+		T = uint8(S[xMax-1] / total)
+	}
+
+	return T
+}
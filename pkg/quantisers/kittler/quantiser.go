@@ -0,0 +1,28 @@
+package kittler
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "kittler", it only supports greyscale quantisation with m = 1
+type Quantiser struct{}
+
+func (Quantiser) Name() string {
+	return "kittler"
+}
+
+func (Quantiser) QuantiseGreyscale(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	if m != 1 {
+		return nil, quantisers.ErrUnsupportedPaletteSize
+	}
+	return QuantiseGreyscale(img), nil
+}
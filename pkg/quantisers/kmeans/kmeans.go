@@ -0,0 +1,185 @@
+package kmeans
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Colour space the k-means iterations are performed in
+type Space int
+
+const (
+	RGB Space = iota
+	LAB
+)
+
+const (
+	DefaultIterations = 16
+	DefaultTolerance  = 0.5
+)
+
+// Options for refining a palette
+type Options struct {
+	Space         Space   // Colour space the clusters are calculated in
+	MaxIterations int     // Maximum number of iterations to run, if zero DefaultIterations is used
+	Tolerance     float64 // Refining stops once no colour moves further than this, if zero DefaultTolerance is used
+
+	WorkingSpace *colours.WorkingSpace // Working space of the image and palette, only used in LAB space, if nil then sRGB is used
+}
+
+// Distinct colour in the image as a vector in the chosen colour space,
+// the last component is the alpha value scaled to the range of the space
+type point struct {
+	v [4]float64
+	n float64 // Number of pixels with the colour
+}
+
+// Refines a palette using Lloyd's k-means algorithm, each iteration assigns every
+// colour in the image to its nearest palette colour, then moves each palette colour to
+// the mean of the colours assigned to it. Any palette can be used as the starting point
+// but one created by a good quantiser such as PNN converges quicker to a lower error
+func Refine(img image.Image, c color.Palette, opts Options) (color.Palette, error) {
+	if len(c) < 1 {
+		return nil, quantisers.ErrEmptyPalette
+	}
+	if img == nil || img.Bounds().Empty() {
+		return nil, quantisers.ErrEmptyImage
+	}
+	if opts.MaxIterations == 0 {
+		opts.MaxIterations = DefaultIterations
+	}
+	if opts.Tolerance == 0 {
+		opts.Tolerance = DefaultTolerance
+	}
+
+	points := createPoints(img, opts)
+	centroids := make([][4]float64, len(c))
+	for i := range c {
+		r, g, b, a := c[i].RGBA()
+		centroids[i] = toVector(r>>8, g>>8, b>>8, a>>8, opts)
+	}
+
+	sums := make([][4]float64, len(centroids))
+	counts := make([]float64, len(centroids))
+	for iteration := 0; iteration < opts.MaxIterations; iteration++ {
+		for i := range sums {
+			sums[i] = [4]float64{}
+			counts[i] = 0
+		}
+
+		// Assign each colour to its nearest centroid
+		for _, p := range points {
+			nearest, dst := 0, math.MaxFloat64
+			for i, cen := range centroids {
+				if d := sqrDistance(p.v, cen); d < dst {
+					nearest, dst = i, d
+				}
+			}
+			for j := range p.v {
+				sums[nearest][j] += p.v[j] * p.n
+			}
+			counts[nearest] += p.n
+		}
+
+		// Move the centroids to the mean of their colours, centroids
+		// with no colours assigned to them are left where they are
+		shift := 0.0
+		for i := range centroids {
+			if counts[i] == 0 {
+				continue
+			}
+			var mean [4]float64
+			for j := range mean {
+				mean[j] = sums[i][j] / counts[i]
+			}
+			shift = math.Max(shift, sqrDistance(mean, centroids[i]))
+			centroids[i] = mean
+		}
+
+		if math.Sqrt(shift) < opts.Tolerance {
+			break
+		}
+	}
+
+	palette := make(color.Palette, len(centroids))
+	for i, cen := range centroids {
+		palette[i] = fromVector(cen, opts)
+	}
+
+	return palette, nil
+}
+
+// Creates a list of all the distinct colours in the image
+func createPoints(img image.Image, opts Options) []point {
+	bounds := img.Bounds()
+	width, height := bounds.Max.X, bounds.Max.Y
+
+	// Colours are packed into a uint32 as RGBA
+	hist := make(map[uint32]int)
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			hist[(r>>8)<<24|(g>>8)<<16|(b>>8)<<8|a>>8]++
+		}
+	}
+
+	points := make([]point, 0, len(hist))
+	for c, n := range hist {
+		v := toVector(c>>24, c>>16&0xFF, c>>8&0xFF, c&0xFF, opts)
+		points = append(points, point{v, float64(n)})
+	}
+
+	return points
+}
+
+// Converts 8-bit RGBA values to a vector in the colour space
+func toVector(r, g, b, a uint32, opts Options) [4]float64 {
+	if opts.Space == LAB {
+		rgb := &colours.RGB{R: float64(r), G: float64(g), B: float64(b)}
+		var lab *colours.LAB
+		if opts.WorkingSpace != nil {
+			lab = opts.WorkingSpace.XYZ(rgb).LAB()
+		} else {
+			lab = rgb.LAB()
+		}
+		return [4]float64{lab.L, lab.A, lab.B, float64(a) * 100 / 255}
+	}
+	return [4]float64{float64(r), float64(g), float64(b), float64(a)}
+}
+
+// Converts a vector in the colour space to an RGBA colour
+func fromVector(v [4]float64, opts Options) color.Color {
+	if opts.Space == LAB {
+		lab := &colours.LAB{L: v[0], A: v[1], B: v[2]}
+		var rgb *colours.RGB
+		if opts.WorkingSpace != nil {
+			rgb = opts.WorkingSpace.RGB(lab.XYZ())
+		} else {
+			rgb = lab.RGB()
+		}
+		return color.RGBA{
+			R: colours.ClampFloatToUint8(rgb.R + 0.5),
+			G: colours.ClampFloatToUint8(rgb.G + 0.5),
+			B: colours.ClampFloatToUint8(rgb.B + 0.5),
+			A: colours.ClampFloatToUint8(v[3]*255/100 + 0.5),
+		}
+	}
+	return color.RGBA{
+		R: colours.ClampFloatToUint8(v[0] + 0.5),
+		G: colours.ClampFloatToUint8(v[1] + 0.5),
+		B: colours.ClampFloatToUint8(v[2] + 0.5),
+		A: colours.ClampFloatToUint8(v[3] + 0.5),
+	}
+}
+
+// Squared euclidean distance between two vectors
+func sqrDistance(a, b [4]float64) float64 {
+	dst := 0.0
+	for i := range a {
+		dst += colours.Sqr(a[i] - b[i])
+	}
+	return dst
+}
@@ -0,0 +1,125 @@
+package kmeans
+
+import (
+	"errors"
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/mediancut"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// Returns a 64x64 image with a smooth gradient of colours
+func gradient() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(4 * x), G: uint8(4 * y), B: uint8(2 * (x + y)), A: 255})
+		}
+	}
+	return img
+}
+
+// Returns the mean squared RGB error of recreating the image with the palette
+func meanError(img image.Image, c color.Palette) float64 {
+	bounds := img.Bounds()
+	var sum float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, _ := img.At(x, y).RGBA()
+			r2, g2, b2, _ := c.Convert(img.At(x, y)).RGBA()
+			sum += colours.Sqr(float64(r1>>8)-float64(r2>>8)) +
+				colours.Sqr(float64(g1>>8)-float64(g2>>8)) +
+				colours.Sqr(float64(b1>>8)-float64(b2>>8))
+		}
+	}
+	return sum / float64(bounds.Dx()*bounds.Dy())
+}
+
+var red, blue = quantisetest.Red, quantisetest.Blue
+
+func TestRefineErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		c    color.Palette
+		err  error
+	}{
+		{"empty palette", quantisetest.Stripes(red), color.Palette{}, quantisers.ErrEmptyPalette},
+		{"nil palette", quantisetest.Stripes(red), nil, quantisers.ErrEmptyPalette},
+		{"empty image", image.NewRGBA(image.Rect(0, 0, 0, 0)), color.Palette{red}, quantisers.ErrEmptyImage},
+		{"nil image", nil, color.Palette{red}, quantisers.ErrEmptyImage},
+	}
+
+	for _, tt := range tests {
+		if _, err := Refine(tt.img, tt.c, Options{}); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestRefine(t *testing.T) {
+	// Colours near the clusters of the image are moved onto them
+	img := quantisetest.Stripes(red, red, blue)
+	initial := color.Palette{color.RGBA{R: 200, G: 40, A: 255}, color.RGBA{R: 30, B: 180, A: 255}}
+
+	tests := map[string]Options{
+		"RGB":            {Space: RGB},
+		"LAB":            {Space: LAB},
+		"LAB in sRGB":    {Space: LAB, WorkingSpace: colours.SRGB},
+		"LAB in P3":      {Space: LAB, WorkingSpace: colours.DisplayP3},
+		"LAB in Rec2020": {Space: LAB, WorkingSpace: colours.Rec2020},
+	}
+
+	for name, opts := range tests {
+		palette, err := Refine(img, initial, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(palette) != 2 || palette[0] != red || palette[1] != blue {
+			t.Errorf("%s: got %v, want [%v %v]", name, palette, red, blue)
+		}
+	}
+}
+
+func TestRefineUnused(t *testing.T) {
+	// Colours with no pixels nearest to them stay where they are
+	green := quantisetest.Green
+	palette, err := Refine(quantisetest.Stripes(red), color.Palette{red, green}, Options{})
+	if err != nil || palette[1] != green {
+		t.Errorf("got %v and error %v, want %v to be kept", palette, err, green)
+	}
+}
+
+func TestRefineLowersError(t *testing.T) {
+	img := gradient()
+	initial := mediancut.QuantiseColour(img, 8)
+
+	palette, err := Refine(img, initial, Options{MaxIterations: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(palette) != len(initial) {
+		t.Errorf("got %d colours, want %d", len(palette), len(initial))
+	}
+	if before, after := meanError(img, initial), meanError(img, palette); after > before {
+		t.Errorf("error rose from %.2f to %.2f", before, after)
+	}
+}
+
+func TestQuantiser(t *testing.T) {
+	q := Quantiser{Initial: mediancut.Quantiser{}}
+	if q.Name() != "mediancut+kmeans" {
+		t.Errorf("got name %q", q.Name())
+	}
+
+	if _, err := q.QuantiseColour(gradient(), 0); !errors.Is(err, quantisers.ErrInvalidPaletteSize) {
+		t.Errorf("got error %v, want %v", err, quantisers.ErrInvalidPaletteSize)
+	}
+	palette, err := q.QuantiseColour(gradient(), 8)
+	if err != nil || len(palette) != 8 {
+		t.Errorf("got %d colours and error %v, want 8", len(palette), err)
+	}
+}
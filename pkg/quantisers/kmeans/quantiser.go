@@ -0,0 +1,27 @@
+package kmeans
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+// Quantiser which refines the palette created by another quantiser with k-means,
+// it isn't registered since it needs the other quantiser to be chosen
+type Quantiser struct {
+	Initial quantisers.ColourQuantiser // Quantiser which creates the starting palette
+	Options
+}
+
+// Name of the initial quantiser with "+kmeans" appended
+func (q Quantiser) Name() string {
+	return q.Initial.Name() + "+kmeans"
+}
+
+func (q Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	c, err := q.Initial.QuantiseColour(img, m)
+	if err != nil {
+		return nil, err
+	}
+	return Refine(img, c, q.Options)
+}
@@ -11,11 +11,21 @@ const (
 	xMin = 0
 )
 
-// Returns "m" greyscale colours to best recreate the colour palette of the original image
+// Returns "m" greyscale colours to best recreate the colour palette of the original image,
+// if the image has less than "m" grey levels then only that many colours are returned.
+// Returns nil if m is less than 1 or the image is empty
 func QuantiseGreyscale(img image.Image, m int) color.Palette {
 	// Create the histogram
 	histogram := quantisers.CreateGreyscaleHistogram(img)
 
+	// Can't create more colours than the image has grey levels
+	if m > len(histogram) {
+		m = len(histogram)
+	}
+	if m < 1 {
+		return nil
+	}
+
 	// Calculate the initial threshold values
 	T := make([]uint8, m+1)
 	for i := 0; i <= m; i++ {
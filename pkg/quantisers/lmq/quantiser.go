@@ -0,0 +1,25 @@
+package lmq
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "lmq", it only supports greyscale quantisation
+type Quantiser struct{}
+
+func (Quantiser) Name() string {
+	return "lmq"
+}
+
+func (Quantiser) QuantiseGreyscale(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	return QuantiseGreyscale(img, m), nil
+}
@@ -0,0 +1,163 @@
+package mediancut
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// Number of bits per channel used for the histogram, Heckbert
+// found reducing colours to 5 bits gives no noticeable loss in quality
+const bits = 5
+
+// Histogram bin holding pixels which share the same reduced colour
+type point struct {
+	c   [3]uint8   // Reduced RGB values of the bin
+	n   int        // Number of pixels in the bin
+	sum [4]float64 // Sum of the RGBA values of the pixels in the bin
+}
+
+// Box in the reduced RGB colour space holding the points which lie in it
+type box struct {
+	points   []point
+	min, max [3]uint8
+}
+
+// Returns a palette of "m" colours to best recreate the image from using
+// Heckbert's median cut, if the image has less than "m" colours then all of
+// them are returned. Returns nil if m is less than 1 or the image is empty
+func QuantiseColour(img image.Image, m int) color.Palette {
+	points := createPoints(img)
+	if m < 1 || len(points) == 0 {
+		return nil
+	}
+
+	boxes := []*box{newBox(points)}
+	for len(boxes) < m {
+		// Split the box with the largest side, boxes
+		// with a single colour can't be split further
+		i, channel := widestBox(boxes)
+		if i < 0 {
+			break
+		}
+		a, b := boxes[i].split(channel)
+		boxes[i] = a
+		boxes = append(boxes, b)
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i, b := range boxes {
+		palette[i] = b.mean()
+	}
+
+	return palette
+}
+
+// Creates a list of all the non empty histogram bins of the image
+func createPoints(img image.Image) []point {
+	bounds := img.Bounds()
+	width, height := bounds.Max.X, bounds.Max.Y
+
+	hist := make([]point, 1<<(3*bits))
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			r, g, b, a = r>>8, g>>8, b>>8, a>>8
+
+			p := &hist[(r>>(8-bits))<<(2*bits)|(g>>(8-bits))<<bits|b>>(8-bits)]
+			p.n++
+			p.sum[0] += float64(r)
+			p.sum[1] += float64(g)
+			p.sum[2] += float64(b)
+			p.sum[3] += float64(a)
+		}
+	}
+
+	points := make([]point, 0)
+	for i, p := range hist {
+		if p.n == 0 {
+			continue
+		}
+		p.c = [3]uint8{uint8(i >> (2 * bits)), uint8(i>>bits) & (1<<bits - 1), uint8(i) & (1<<bits - 1)}
+		points = append(points, p)
+	}
+
+	return points
+}
+
+// Creates a box which tightly bounds the points
+func newBox(points []point) *box {
+	b := &box{points: points}
+	b.min = points[0].c
+	b.max = points[0].c
+	for _, p := range points[1:] {
+		for i, v := range p.c {
+			if v < b.min[i] {
+				b.min[i] = v
+			}
+			if v > b.max[i] {
+				b.max[i] = v
+			}
+		}
+	}
+
+	return b
+}
+
+// Returns the index of the box with the largest side and which channel
+// that side is for, returns -1 if no box can be split
+func widestBox(boxes []*box) (int, int) {
+	index, channel, widest := -1, 0, 0
+	for i, b := range boxes {
+		if len(b.points) < 2 {
+			continue
+		}
+		for c := range b.min {
+			if w := int(b.max[c]) - int(b.min[c]); w > widest {
+				index, channel, widest = i, c, w
+			}
+		}
+	}
+
+	return index, channel
+}
+
+// Splits the box into two at the median pixel along the channel
+func (b *box) split(channel int) (*box, *box) {
+	sort.Slice(b.points, func(i, j int) bool {
+		return b.points[i].c[channel] < b.points[j].c[channel]
+	})
+
+	total := 0
+	for _, p := range b.points {
+		total += p.n
+	}
+
+	// Both boxes must contain at least one point
+	median, count := 1, b.points[0].n
+	for median < len(b.points)-1 && count < total/2 {
+		count += b.points[median].n
+		median++
+	}
+
+	return newBox(b.points[:median]), newBox(b.points[median:])
+}
+
+// Returns the mean colour of the pixels in the box
+func (b *box) mean() color.Color {
+	var sum [4]float64
+	var total float64
+	for _, p := range b.points {
+		for i, v := range p.sum {
+			sum[i] += v
+		}
+		total += float64(p.n)
+	}
+
+	return color.RGBA{
+		R: uint8(sum[0]/total + 0.5),
+		G: uint8(sum[1]/total + 0.5),
+		B: uint8(sum[2]/total + 0.5),
+		A: uint8(sum[3]/total + 0.5),
+	}
+}
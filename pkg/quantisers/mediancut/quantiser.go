@@ -0,0 +1,25 @@
+package mediancut
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "mediancut", it only supports colour quantisation
+type Quantiser struct{}
+
+func (Quantiser) Name() string {
+	return "mediancut"
+}
+
+func (Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	return QuantiseColour(img, m), nil
+}
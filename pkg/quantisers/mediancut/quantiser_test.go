@@ -0,0 +1,31 @@
+package mediancut
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"image/color"
+	"testing"
+)
+
+func TestQuantiseColourExact(t *testing.T) {
+	// Colours in different bins are kept apart and returned as their mean, which is themselves
+	colours := []color.Color{quantisetest.Red, quantisetest.Green, quantisetest.Blue}
+	palette := QuantiseColour(quantisetest.Stripes(colours...), 3)
+	for i, want := range colours {
+		found := false
+		for _, c := range palette {
+			found = found || c == want
+		}
+		if !found {
+			t.Errorf("%d: %v missing from palette %v", i, want, palette)
+		}
+	}
+}
+
+func TestQuantiseColourMean(t *testing.T) {
+	// Colours sharing a bin can't be split so their mean is returned
+	palette := QuantiseColour(quantisetest.Stripes(color.RGBA{R: 100, A: 255}, color.RGBA{R: 102, A: 255}), 2)
+	want := color.RGBA{R: 101, A: 255}
+	if len(palette) != 1 || palette[0] != want {
+		t.Errorf("got %v, want [%v]", palette, want)
+	}
+}
@@ -0,0 +1,317 @@
+package neuquant
+
+import (
+	"image"
+	"image/color"
+)
+
+// Sampling factor used by QuantiseColour, 1 samples every pixel and 30 samples
+// roughly one in thirty pixels which is the fastest setting but lowest quality
+const (
+	DefaultSampleFactor = 10
+	MinSampleFactor     = 1
+	MaxSampleFactor     = 30
+)
+
+// Constants from Anthony Dekker's NeuQuant implementation, most values
+// are fixed point numbers with the number of fractional bits given by their shift
+const (
+	ncycles = 100 // Number of learning cycles
+
+	netBiasShift = 4 // Bias for colour values
+	intBiasShift = 16
+	intBias      = 1 << intBiasShift // Bias for fractions
+	gammaShift   = 10
+	betaShift    = 10
+	beta         = intBias >> betaShift // beta = 1/1024
+	betaGamma    = intBias << (gammaShift - betaShift)
+
+	radiusBiasShift = 6 // For the neighbourhood radius
+	radiusBias      = 1 << radiusBiasShift
+	radiusDec       = 30 // Factor of 1/30 each cycle
+
+	alphaBiasShift = 10 // For the learning rate
+	initAlpha      = 1 << alphaBiasShift
+
+	radBiasShift   = 8
+	radBias        = 1 << radBiasShift
+	alphaRadBShift = alphaBiasShift + radBiasShift
+	alphaRadBias   = 1 << alphaRadBShift
+
+	// Four primes near 500, the image is sampled in steps of one of
+	// these so that successive samples aren't correlated
+	prime1 = 499
+	prime2 = 491
+	prime3 = 487
+	prime4 = 503
+
+	minPicturePixels = prime4
+)
+
+// Kohonen self organising map of colours
+type network struct {
+	neurons  [][4]int // BGRA values of each neuron
+	bias     []int    // Bias and frequency arrays for learning
+	freq     []int
+	wins     []int // Number of samples each neuron has won, neurons which never win aren't used
+	radPower []int // Precomputed learning rates for the neighbourhood
+}
+
+// Returns a palette of "m" colours to best recreate the image from using
+// Anthony Dekker's NeuQuant neural network with the default sampling factor. The palette has
+// at most "m" colours, neurons which never learn a colour are left out. Returns nil if m is less
+// than 1 or the image is empty
+func QuantiseColour(img image.Image, m int) color.Palette {
+	return QuantiseColourSampled(img, m, DefaultSampleFactor)
+}
+
+// Returns a palette of "m" colours to best recreate the image from using Anthony Dekker's
+// NeuQuant neural network. The network learns from one in every "factor" pixels so a lower
+// factor gives better quality but takes longer, it is clamped to the range 1-30. If the image
+// has less than "m" colours then all of them are returned, otherwise neurons which never learn
+// a colour are left out. Returns nil if m is less than 1 or the image is empty
+func QuantiseColourSampled(img image.Image, m, factor int) color.Palette {
+	if m < 1 || img.Bounds().Empty() {
+		return nil
+	}
+	if factor < MinSampleFactor {
+		factor = MinSampleFactor
+	} else if factor > MaxSampleFactor {
+		factor = MaxSampleFactor
+	}
+
+	pixels, colours := createPixels(img, m)
+	if len(colours) <= m {
+		return colours
+	}
+
+	net := newNetwork(m)
+	net.learn(pixels, factor)
+
+	palette := make(color.Palette, 0, m)
+	for i, n := range net.neurons {
+		if net.wins[i] == 0 {
+			continue
+		}
+
+		// The pixels are premultiplied so the colour can't be brighter than its alpha
+		a := clamp(n[3] >> netBiasShift)
+		palette = append(palette, color.RGBA{
+			R: minUint8(clamp(n[2]>>netBiasShift), a),
+			G: minUint8(clamp(n[1]>>netBiasShift), a),
+			B: minUint8(clamp(n[0]>>netBiasShift), a),
+			A: a,
+		})
+	}
+
+	return palette
+}
+
+// Lists the BGRA values of the pixels in the image, along with its distinct
+// colours if there are no more than "m" of them, otherwise more than m are returned
+func createPixels(img image.Image, m int) ([]byte, color.Palette) {
+	bounds := img.Bounds()
+	width, height := bounds.Max.X, bounds.Max.Y
+
+	pixels := make([]byte, 0, 4*bounds.Dx()*bounds.Dy())
+	seen := make(map[color.RGBA]bool)
+	colours := make(color.Palette, 0)
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			c := color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+			pixels = append(pixels, c.B, c.G, c.R, c.A)
+
+			if len(colours) <= m && !seen[c] {
+				seen[c] = true
+				colours = append(colours, c)
+			}
+		}
+	}
+
+	return pixels, colours
+}
+
+// Creates a network whose neurons start opaque and evenly spaced along the grey axis
+func newNetwork(size int) *network {
+	net := &network{
+		neurons:  make([][4]int, size),
+		bias:     make([]int, size),
+		freq:     make([]int, size),
+		wins:     make([]int, size),
+		radPower: make([]int, size>>3+1),
+	}
+	for i := range net.neurons {
+		v := (i << (netBiasShift + 8)) / size
+		net.neurons[i] = [4]int{v, v, v, 255 << netBiasShift}
+		net.freq[i] = intBias / size
+	}
+
+	return net
+}
+
+// Trains the network on a sample of the pixels
+func (net *network) learn(pixels []byte, factor int) {
+	length, count := len(pixels), len(pixels)/4
+	if count < minPicturePixels {
+		factor = 1
+	}
+	alphaDec := 30 + (factor-1)/3
+	samplePixels := count / factor
+	delta := samplePixels / ncycles
+	if delta == 0 {
+		delta = 1
+	}
+
+	alpha := initAlpha
+	radius := (len(net.neurons) >> 3) * radiusBias
+	rad := radius >> radiusBiasShift
+	if rad <= 1 {
+		rad = 0
+	}
+	net.setRadPower(rad, alpha)
+
+	var step int
+	switch {
+	case count < minPicturePixels:
+		step = 4
+	case count%prime1 != 0:
+		step = 4 * prime1
+	case count%prime2 != 0:
+		step = 4 * prime2
+	case count%prime3 != 0:
+		step = 4 * prime3
+	default:
+		step = 4 * prime4
+	}
+
+	pix := 0
+	for i := 0; i < samplePixels; {
+		b := int(pixels[pix]) << netBiasShift
+		g := int(pixels[pix+1]) << netBiasShift
+		r := int(pixels[pix+2]) << netBiasShift
+		a := int(pixels[pix+3]) << netBiasShift
+
+		j := net.contest(b, g, r, a)
+		net.alterSingle(alpha, j, b, g, r, a)
+		if rad != 0 {
+			net.alterNeighbours(rad, j, b, g, r, a)
+		}
+
+		pix += step
+		if pix >= length {
+			pix -= length
+		}
+
+		i++
+		if i%delta == 0 {
+			alpha -= alpha / alphaDec
+			radius -= radius / radiusDec
+			rad = radius >> radiusBiasShift
+			if rad <= 1 {
+				rad = 0
+			}
+			net.setRadPower(rad, alpha)
+		}
+	}
+}
+
+// Precomputes the learning rate for each distance from the winning neuron
+func (net *network) setRadPower(rad, alpha int) {
+	for i := 0; i < rad; i++ {
+		net.radPower[i] = alpha * (((rad*rad - i*i) * radBias) / (rad * rad))
+	}
+}
+
+// Finds the neuron closest to the colour, using a bias so that neurons which rarely
+// win are favoured. Returns the index of the biased winner
+func (net *network) contest(b, g, r, a int) int {
+	bestD, bestBiasD := int(^uint32(0)>>1), int(^uint32(0)>>1)
+	bestPos, bestBiasPos := -1, -1
+
+	for i, n := range net.neurons {
+		dist := abs(n[0]-b) + abs(n[1]-g) + abs(n[2]-r) + abs(n[3]-a)
+		if dist < bestD {
+			bestD, bestPos = dist, i
+		}
+		biasDist := dist - (net.bias[i] >> (intBiasShift - netBiasShift))
+		if biasDist < bestBiasD {
+			bestBiasD, bestBiasPos = biasDist, i
+		}
+		betaFreq := net.freq[i] >> betaShift
+		net.freq[i] -= betaFreq
+		net.bias[i] += betaFreq << gammaShift
+	}
+	net.wins[bestBiasPos]++
+	net.freq[bestPos] += beta
+	net.bias[bestPos] -= betaGamma
+
+	return bestBiasPos
+}
+
+// Moves the neuron towards the colour by a factor of alpha
+func (net *network) alterSingle(alpha, i, b, g, r, a int) {
+	n := &net.neurons[i]
+	n[0] -= (alpha * (n[0] - b)) / initAlpha
+	n[1] -= (alpha * (n[1] - g)) / initAlpha
+	n[2] -= (alpha * (n[2] - r)) / initAlpha
+	n[3] -= (alpha * (n[3] - a)) / initAlpha
+}
+
+// Moves the neighbours of the neuron within the radius towards the colour,
+// neighbours further away from the neuron are moved less
+func (net *network) alterNeighbours(rad, i, b, g, r, a int) {
+	lo, hi := i-rad, i+rad
+	if lo < -1 {
+		lo = -1
+	}
+	if hi > len(net.neurons) {
+		hi = len(net.neurons)
+	}
+
+	j, k, m := i+1, i-1, 1
+	for j < hi || k > lo {
+		p := net.radPower[m]
+		m++
+		if j < hi {
+			n := &net.neurons[j]
+			n[0] -= (p * (n[0] - b)) / alphaRadBias
+			n[1] -= (p * (n[1] - g)) / alphaRadBias
+			n[2] -= (p * (n[2] - r)) / alphaRadBias
+			n[3] -= (p * (n[3] - a)) / alphaRadBias
+			j++
+		}
+		if k > lo {
+			n := &net.neurons[k]
+			n[0] -= (p * (n[0] - b)) / alphaRadBias
+			n[1] -= (p * (n[1] - g)) / alphaRadBias
+			n[2] -= (p * (n[2] - r)) / alphaRadBias
+			n[3] -= (p * (n[3] - a)) / alphaRadBias
+			k--
+		}
+	}
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func clamp(a int) uint8 {
+	if a < 0 {
+		return 0
+	}
+	if a > 255 {
+		return 255
+	}
+	return uint8(a)
+}
+
+func minUint8(a, b uint8) uint8 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,30 @@
+package neuquant
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "neuquant", it only supports colour quantisation
+type Quantiser struct {
+	SampleFactor int // Learn from one in every SampleFactor pixels, if zero then DefaultSampleFactor is used
+}
+
+func (Quantiser) Name() string {
+	return "neuquant"
+}
+
+func (q Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	if q.SampleFactor == 0 {
+		return QuantiseColour(img, m), nil
+	}
+	return QuantiseColourSampled(img, m, q.SampleFactor), nil
+}
@@ -0,0 +1,59 @@
+package neuquant
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// Returns a gradient with many colours, all of them with the given alpha
+func gradient(a uint8) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(4 * x), G: uint8(4 * y), B: 128, A: a})
+		}
+	}
+	return img
+}
+
+func TestQuantiseColourExact(t *testing.T) {
+	// Images with no more than m colours skip the network for every sampling factor
+	colours := []color.Color{quantisetest.Red, quantisetest.Green, quantisetest.Blue}
+	for _, q := range []Quantiser{{}, {SampleFactor: 1}, {SampleFactor: 30}} {
+		palette, err := q.QuantiseColour(quantisetest.Stripes(colours...), 4)
+		if err != nil || len(palette) != len(colours) {
+			t.Fatalf("factor %d: got %v and error %v", q.SampleFactor, palette, err)
+		}
+		for i, want := range colours {
+			found := false
+			for _, c := range palette {
+				found = found || c == want
+			}
+			if !found {
+				t.Errorf("factor %d, %d: %v missing from palette %v", q.SampleFactor, i, want, palette)
+			}
+		}
+	}
+}
+
+func TestQuantiseColourManyColours(t *testing.T) {
+	for _, m := range []int{1, 16, 256} {
+		palette := QuantiseColour(gradient(255), m)
+		if len(palette) < 1 || len(palette) > m {
+			t.Errorf("%d: got %d colours", m, len(palette))
+		}
+	}
+}
+
+func TestQuantiseColourAlpha(t *testing.T) {
+	for _, a := range []uint8{0, 64, 128, 255} {
+		for _, c := range QuantiseColourSampled(gradient(a), 16, 1) {
+			_, _, _, got := c.RGBA()
+			if diff := int(got>>8) - int(a); diff < -1 || diff > 1 {
+				t.Errorf("alpha %d: got colour %v", a, c)
+			}
+		}
+	}
+}
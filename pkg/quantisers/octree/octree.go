@@ -0,0 +1,263 @@
+package octree
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+const (
+	// Default depth of the tree, i.e. one level for every bit of a colour channel
+	DefaultDepth = 8
+	// Maximum number of leaves the tree holds while it is being built,
+	// this bounds the memory used regardless of the size of the image
+	maxLeaves = 1024
+)
+
+// Node of the octree, each level of the tree splits the colour space in half along
+// every channel. Since alpha is included each node has 16 children instead of 8
+type node struct {
+	children [16]*node
+	leaf     bool
+	total    int       // Number of pixels in the node and its descendants
+	n        int       // Number of pixels in the node
+	sum      [4]uint64 // Sum of the RGBA values of the pixels in the node
+	next     *node     // Next reducible node on the same level
+}
+
+// Octree which reduces the colours inserted into it
+type tree struct {
+	root      *node
+	depth     int
+	leaves    int
+	reducible []*node // Linked lists of nodes which have children, one per level
+}
+
+// Returns a palette of "m" colours to best recreate the image from using an
+// octree of the default depth, if the image has less than "m" colours then
+// all of them are returned. Returns nil if m is less than 1 or the image is empty
+func QuantiseColour(img image.Image, m int) color.Palette {
+	return QuantiseColourDepth(img, m, DefaultDepth)
+}
+
+// Returns a palette of "m" colours to best recreate the image from using an
+// octree with the given depth in the range 1-8. A smaller depth uses less memory
+// and is faster but groups similar colours together before any reduction happens.
+// Returns nil if m is less than 1 or the image is empty
+func QuantiseColourDepth(img image.Image, m, depth int) color.Palette {
+	bounds := img.Bounds()
+	if m < 1 || bounds.Empty() {
+		return nil
+	}
+	if depth < 1 {
+		depth = 1
+	} else if depth > DefaultDepth {
+		depth = DefaultDepth
+	}
+
+	// The tree needs to hold at least m leaves so that
+	// the final reduction has enough colours to pick from
+	limit := maxLeaves
+	if m > limit {
+		limit = m
+	}
+
+	t := &tree{
+		root:      &node{},
+		depth:     depth,
+		reducible: make([]*node, depth),
+	}
+
+	width, height := bounds.Max.X, bounds.Max.Y
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			t.insert([4]uint32{r >> 8, g >> 8, b >> 8, a >> 8})
+			for t.leaves > limit {
+				t.reduce(0)
+			}
+		}
+	}
+
+	// Reducing a node can remove up to 15 leaves at once, so once no
+	// node can be reduced without going below m the closest leaves are merged
+	for t.leaves > m && t.reduce(m) {
+	}
+	leaves := mergeLeaves(t.root.leaves(nil), m)
+
+	palette := make(color.Palette, len(leaves))
+	for i, l := range leaves {
+		palette[i] = l.colour()
+	}
+
+	return palette
+}
+
+// Adds a colour to the tree, creating the nodes on its path if they don't exist
+func (t *tree) insert(c [4]uint32) {
+	n := t.root
+	n.total++
+	for level := 0; level < t.depth && !n.leaf; level++ {
+		i := childIndex(c, level)
+		if n.children[i] == nil {
+			child := &node{}
+			if level == t.depth-1 {
+				child.leaf = true
+				t.leaves++
+			}
+			n.children[i] = child
+
+			// The node now has children so it can be reduced,
+			// if it isn't in its level's reducible list it is added
+			if !n.reducibleSet() {
+				n.next = t.reducible[level]
+				t.reducible[level] = n
+			}
+		}
+		n = n.children[i]
+		n.total++
+	}
+
+	n.n++
+	for i, v := range c {
+		n.sum[i] += uint64(v)
+	}
+}
+
+// Whether the node has already been added to a reducible list, this is true
+// if it has more than one child since the first child adds the node to the list
+func (n *node) reducibleSet() bool {
+	return n.childCount() > 1
+}
+
+// Number of children the node has
+func (n *node) childCount() int {
+	count := 0
+	for _, c := range n.children {
+		if c != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Merges the children of a node in the deepest reducible level into it. If keep is 0 then the
+// most recently added node is chosen which is quick while the tree is being built. Otherwise
+// the node representing the fewest pixels, which leaves at least keep leaves in the tree, is
+// chosen so prominent colours are kept. Returns whether a node was reduced
+func (t *tree) reduce(keep int) bool {
+	level := len(t.reducible) - 1
+	for level >= 0 && t.reducible[level] == nil {
+		level--
+	}
+	if level < 0 {
+		return false
+	}
+
+	var prev, best, bestPrev *node
+	if keep == 0 {
+		best = t.reducible[level]
+	} else {
+		for n := t.reducible[level]; n != nil; prev, n = n, n.next {
+			if t.leaves-n.childCount()+1 < keep {
+				continue
+			}
+			if best == nil || n.total < best.total {
+				best, bestPrev = n, prev
+			}
+		}
+		if best == nil {
+			return false
+		}
+	}
+	if bestPrev == nil {
+		t.reducible[level] = best.next
+	} else {
+		bestPrev.next = best.next
+	}
+
+	// Combine the children into the node, they must all be leaves since
+	// there are no reducible nodes in the levels below this one
+	removed := 0
+	for i, c := range best.children {
+		if c == nil {
+			continue
+		}
+		best.n += c.n
+		for j := range c.sum {
+			best.sum[j] += c.sum[j]
+		}
+		best.children[i] = nil
+		removed++
+	}
+	best.leaf = true
+	best.next = nil
+	t.leaves -= removed - 1
+
+	return true
+}
+
+// Appends the leaves under the node to the slice
+func (n *node) leaves(leaves []*node) []*node {
+	if n.leaf {
+		return append(leaves, n)
+	}
+	for _, c := range n.children {
+		if c != nil {
+			leaves = c.leaves(leaves)
+		}
+	}
+	return leaves
+}
+
+// Mean colour of the pixels in the node
+func (n *node) colour() color.Color {
+	N := uint64(n.n)
+	return color.RGBA{
+		R: uint8((n.sum[0] + N/2) / N),
+		G: uint8((n.sum[1] + N/2) / N),
+		B: uint8((n.sum[2] + N/2) / N),
+		A: uint8((n.sum[3] + N/2) / N),
+	}
+}
+
+// Merges the pair of leaves whose merge increases the squared error
+// the least until only "m" remain, the leaves are no longer part of the tree
+func mergeLeaves(leaves []*node, m int) []*node {
+	for len(leaves) > m {
+		a, b, cost := 0, 1, math.MaxFloat64
+		for i := range leaves {
+			for j := i + 1; j < len(leaves); j++ {
+				if c := mergeCost(leaves[i], leaves[j]); c < cost {
+					a, b, cost = i, j, c
+				}
+			}
+		}
+
+		leaves[a].n += leaves[b].n
+		for i := range leaves[a].sum {
+			leaves[a].sum[i] += leaves[b].sum[i]
+		}
+		leaves = append(leaves[:b], leaves[b+1:]...)
+	}
+
+	return leaves
+}
+
+// Increase in the squared error caused by merging two leaves
+func mergeCost(a, b *node) float64 {
+	na, nb := float64(a.n), float64(b.n)
+	dst := 0.0
+	for i := range a.sum {
+		d := float64(a.sum[i])/na - float64(b.sum[i])/nb
+		dst += d * d
+	}
+	return (na * nb) / (na + nb) * dst
+}
+
+// Index of the child a colour belongs to at a given level of the tree,
+// it is made up of the bit at that level from each of the channels
+func childIndex(c [4]uint32, level int) int {
+	shift := uint(7 - level)
+	return int((c[0]>>shift&1)<<3 | (c[1]>>shift&1)<<2 | (c[2]>>shift&1)<<1 | c[3]>>shift&1)
+}
@@ -0,0 +1,30 @@
+package octree
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "octree", it only supports colour quantisation
+type Quantiser struct {
+	Depth int // Depth of the octree, if zero then DefaultDepth is used
+}
+
+func (Quantiser) Name() string {
+	return "octree"
+}
+
+func (q Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	if q.Depth == 0 {
+		return QuantiseColour(img, m), nil
+	}
+	return QuantiseColourDepth(img, m, q.Depth), nil
+}
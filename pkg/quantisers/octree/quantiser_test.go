@@ -0,0 +1,60 @@
+package octree
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// Returns an image with more colours than the tree holds while it is built
+func gradient() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(4 * x), G: uint8(4 * y), B: uint8(2 * (x + y)), A: 255})
+		}
+	}
+	return img
+}
+
+var red, green, blue = quantisetest.Red, quantisetest.Green, quantisetest.Blue
+
+func TestQuantiseColourManyColours(t *testing.T) {
+	// A tree with a depth of one has at most a leaf for each of its 16 children
+	tests := []struct {
+		depth int
+		m     int
+		want  int
+	}{
+		{0, 16, 16},
+		{0, 256, 256},
+		{4, 16, 16},
+		{1, 16, 6},
+	}
+
+	for _, tt := range tests {
+		palette, err := Quantiser{Depth: tt.depth}.QuantiseColour(gradient(), tt.m)
+		if err != nil {
+			t.Fatalf("depth %d: %v", tt.depth, err)
+		}
+		if len(palette) != tt.want {
+			t.Errorf("depth %d, m %d: got %d colours, want %d", tt.depth, tt.m, len(palette), tt.want)
+		}
+	}
+}
+
+func TestQuantiseColourExact(t *testing.T) {
+	// Colours in different nodes are kept apart and returned unchanged, alpha included
+	transparent := color.RGBA{}
+	palette := QuantiseColour(quantisetest.Stripes(red, green, blue, transparent), 4)
+	for i, want := range []color.Color{red, green, blue, transparent} {
+		found := false
+		for _, c := range palette {
+			found = found || c == want
+		}
+		if !found {
+			t.Errorf("%d: %v missing from palette %v", i, want, palette)
+		}
+	}
+}
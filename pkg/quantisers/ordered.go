@@ -0,0 +1,139 @@
+package quantisers
+
+import (
+	"math"
+	"sort"
+)
+
+// Threshold map used by ordered dithering, indexed by [y][x] and tiled over the image.
+// Entries are levels from zero up to the largest entry, such as the rank of each cell,
+// and pixels are pushed towards darker colours where the level is low
+type ThresholdMap [][]float64
+
+// Returns whether the map is not empty and every row has the same length
+func (m ThresholdMap) valid() bool {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return false
+	}
+	for _, row := range m {
+		if len(row) != len(m[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the number of levels in the map, which is one more than its largest entry
+func (m ThresholdMap) levels() float64 {
+	max := 0.0
+	for _, row := range m {
+		for _, v := range row {
+			max = math.Max(max, v)
+		}
+	}
+	return max + 1
+}
+
+// Returns the map with its rows and columns swapped
+func (m ThresholdMap) transpose() ThresholdMap {
+	t := make(ThresholdMap, len(m[0]))
+	for x := range t {
+		t[x] = make([]float64, len(m))
+		for y := range m {
+			t[x][y] = m[y][x]
+		}
+	}
+	return t
+}
+
+// Returns the size x size Bayer matrix, it's built recursively from the 2x2 matrix
+// with each entry of a matrix expanded into the 2x2 matrix scaled by four. The size
+// must be a power of two
+func BayerMatrix(size int) ThresholdMap {
+	if size < 1 || size&(size-1) != 0 {
+		return nil
+	}
+
+	m := ThresholdMap{{0}}
+	for n := 1; n < size; n *= 2 {
+		next := make(ThresholdMap, 2*n)
+		for y := range next {
+			next[y] = make([]float64, 2*n)
+			for x := range next[y] {
+				next[y][x] = 4*m[y%n][x%n] + bayerMatrix2x2[y/n][x/n]
+			}
+		}
+		m = next
+	}
+
+	return m
+}
+
+var bayerMatrix2x2 = [2][2]float64{
+	{0, 2},
+	{3, 1},
+}
+
+// Returns a size x size clustered dot threshold map, which dithers into a
+// grid of round dots growing from the centre of each cell like a halftone
+func ClusteredDot(size int) ThresholdMap {
+	c := float64(size-1) / 2
+	return rankedMap(size, size, func(x, y int) (float64, float64) {
+		dx, dy := float64(x)-c, float64(y)-c
+		return math.Hypot(dx, dy), math.Atan2(dy, dx)
+	})
+}
+
+// Returns a threshold map with one column and size rows, which dithers
+// into horizontal lines that thicken outwards from the middle row
+func HorizontalLines(size int) ThresholdMap {
+	c := float64(size-1) / 2
+	return rankedMap(size, 1, func(x, y int) (float64, float64) {
+		return math.Abs(float64(y) - c), float64(y)
+	})
+}
+
+// Returns a threshold map with one row and size columns, which dithers
+// into vertical lines that thicken outwards from the middle column
+func VerticalLines(size int) ThresholdMap {
+	c := float64(size-1) / 2
+	return rankedMap(1, size, func(x, y int) (float64, float64) {
+		return math.Abs(float64(x) - c), float64(x)
+	})
+}
+
+// Returns a rows x cols map where each cell is ranked by the key of its
+// position, cells with equal keys are ordered by the tiebreak
+func rankedMap(rows, cols int, key func(x, y int) (float64, float64)) ThresholdMap {
+	if rows < 1 || cols < 1 {
+		return nil
+	}
+
+	type cell struct {
+		x, y          int
+		key, tiebreak float64
+	}
+	cells := make([]cell, 0, rows*cols)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			k, t := key(x, y)
+			cells = append(cells, cell{x, y, k, t})
+		}
+	}
+	sort.SliceStable(cells, func(i, j int) bool {
+		if cells[i].key != cells[j].key {
+			return cells[i].key < cells[j].key
+		}
+		return cells[i].tiebreak < cells[j].tiebreak
+	})
+
+	m := make(ThresholdMap, rows)
+	for y := range m {
+		m[y] = make([]float64, cols)
+	}
+	for rank, c := range cells {
+		m[c.y][c.x] = float64(rank)
+	}
+
+	return m
+}
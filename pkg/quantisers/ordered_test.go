@@ -0,0 +1,137 @@
+package quantisers
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// Bayer matrices which were typed out by hand before they were generated
+var bayerTables = map[int]ThresholdMap{
+	2: {
+		{0, 2},
+		{3, 1},
+	},
+	4: {
+		{0, 8, 2, 10},
+		{12, 4, 14, 6},
+		{3, 11, 1, 9},
+		{15, 7, 13, 5},
+	},
+	8: {
+		{0, 32, 8, 40, 2, 34, 10, 42},
+		{48, 16, 56, 24, 50, 18, 58, 26},
+		{12, 44, 4, 36, 14, 46, 6, 38},
+		{60, 28, 52, 20, 62, 30, 54, 22},
+		{3, 35, 11, 43, 1, 33, 9, 41},
+		{51, 19, 59, 27, 49, 17, 57, 25},
+		{15, 47, 7, 39, 13, 45, 5, 37},
+		{63, 31, 55, 23, 61, 29, 53, 21},
+	},
+}
+
+// Returns whether the map holds every rank from zero to rows*cols-1 exactly once
+func ranked(m ThresholdMap) bool {
+	seen := make(map[float64]bool)
+	for _, row := range m {
+		for _, v := range row {
+			if seen[v] || v < 0 || int(v) >= len(m)*len(row) || v != float64(int(v)) {
+				return false
+			}
+			seen[v] = true
+		}
+	}
+	return true
+}
+
+func TestBayerMatrix(t *testing.T) {
+	for size, want := range bayerTables {
+		if got := BayerMatrix(size); !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: got %v, want %v", size, got, want)
+		}
+	}
+	for _, size := range []int{1, 16, 32, 64} {
+		if m := BayerMatrix(size); len(m) != size || !ranked(m) {
+			t.Errorf("%d: got %v", size, m)
+		}
+	}
+	for _, size := range []int{-2, 0, 3, 6, 12} {
+		if m := BayerMatrix(size); m != nil {
+			t.Errorf("%d: got %v, want nil", size, m)
+		}
+	}
+}
+
+func TestThresholdMaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		m          ThresholdMap
+		rows, cols int
+	}{
+		{"ClusteredDot", ClusteredDot(6), 6, 6},
+		{"HorizontalLines", HorizontalLines(4), 4, 1},
+		{"VerticalLines", VerticalLines(5), 1, 5},
+	}
+
+	for _, tt := range tests {
+		if len(tt.m) != tt.rows || len(tt.m[0]) != tt.cols || !ranked(tt.m) || !tt.m.valid() {
+			t.Errorf("%s: got %v", tt.name, tt.m)
+		}
+	}
+
+	// Dots grow from the centre of the cell
+	if dot := ClusteredDot(5); dot[2][2] != 0 {
+		t.Errorf("ClusteredDot: centre has rank %v", dot[2][2])
+	}
+}
+
+// Bayer dithering as it was before the matrices were generated, which indexed them by [x][y]
+func referenceBayer(img image.Image, c color.Palette, matrix ThresholdMap) *image.RGBA {
+	rowL := len(matrix[0])
+	mSize := float64(rowL * rowL)
+	spread := averageColourSpread(c)
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			m := matrix[x%rowL][y%rowL]/mSize - 0.5
+			r, g, b, _ := img.At(x, y).RGBA()
+			out.Set(x, y, c.Convert(color.RGBA{
+				R: colours.ClampFloatToUint8(float64(r>>8) + spread*m),
+				G: colours.ClampFloatToUint8(float64(g>>8) + spread*m),
+				B: colours.ClampFloatToUint8(float64(b>>8) + spread*m),
+				A: 255,
+			}))
+		}
+	}
+	return out
+}
+
+func TestBayerDither(t *testing.T) {
+	img := gradient()
+	for size, d := range map[int]DitherType{2: Bayer2x2, 4: Bayer4x4, 8: Bayer8x8} {
+		got, err := ImageFromPalette(img, testPalette, d)
+		if err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		if !equal(got, referenceBayer(img, testPalette, bayerTables[size])) {
+			t.Errorf("%d: image differs from the hand typed matrix", size)
+		}
+	}
+}
+
+func TestCustomThresholdMap(t *testing.T) {
+	// Non-square maps are tiled over the image
+	img := gradient()
+	for _, m := range []ThresholdMap{HorizontalLines(4), VerticalLines(3), ClusteredDot(6)} {
+		got, err := ImageFromPaletteWithOpts(img, testPalette, Options{Dither: CustomThresholdMap, ThresholdMap: m})
+		if err != nil {
+			t.Fatalf("%v: %v", m, err)
+		}
+		if !onlyPalette(got, testPalette) {
+			t.Errorf("%v: image has colours outside of the palette", m)
+		}
+	}
+}
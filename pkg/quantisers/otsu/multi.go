@@ -0,0 +1,121 @@
+package otsu
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisers"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Returns the m+1 greyscale colours which best recreate the image when it's split into
+// m+1 classes by "m" thresholds using multi-level Otsu thresholding, each colour is the
+// mean grey level of its class. An image with n grey levels has at most n-1 thresholds
+// so at most n colours are returned, the thresholds themselves are given by Thresholds.
+// Returns nil if m is less than 1 or the image is empty
+func QuantiseGreyscaleMulti(img image.Image, m int) color.Palette {
+	hist := quantisers.CreateGreyscaleHistogram(img)
+	if m < 1 || len(hist) == 0 {
+		return nil
+	}
+
+	if m > len(hist)-1 {
+		m = len(hist) - 1
+	}
+	var T []uint8
+	if m > 0 {
+		T = calculateThresholds(hist, m)
+	}
+
+	return classMeans(hist, T)
+}
+
+// Returns the mean grey level of each class the thresholds split the histogram into
+func classMeans(hist quantisers.LinearHistogram, T []uint8) color.Palette {
+	colours := make(color.Palette, 0, len(T)+1)
+	var p, s int
+	class := 0
+	for v := 0; v < xMax; v++ {
+		p += hist[uint8(v)]
+		s += v * hist[uint8(v)]
+		if class < len(T) && v == int(T[class]) || v == xMax-1 {
+			if p > 0 {
+				colours = append(colours, color.Gray{uint8((s + p/2) / p)})
+			}
+			p, s = 0, 0
+			class++
+		}
+	}
+
+	return colours
+}
+
+// Returns the "n" thresholds which split the greyscale image into n+1 classes with
+// the largest between class variance. Pixels with a grey level less than or equal
+// to a threshold belong to the class below it. Returns nil if the image doesn't
+// have more than n grey levels
+func Thresholds(img image.Image, n int) []uint8 {
+	hist := quantisers.CreateGreyscaleHistogram(img)
+	if n < 1 || n >= len(hist) {
+		return nil
+	}
+	return calculateThresholds(hist, n)
+}
+
+// Calculates the thresholds using dynamic programming, maximising the between class
+// variance is the same as maximising the sum of S^2/P over the classes where S is the sum
+// of the grey levels in the class and P is the number of pixels in the class. Classes
+// are never left empty so the histogram must have more than n grey levels
+func calculateThresholds(hist quantisers.LinearHistogram, n int) []uint8 {
+	// Cumulative number of pixels and sum of their grey levels, P[v] and
+	// S[v] hold the totals for all grey levels less than v
+	P := make([]float64, xMax+1)
+	S := make([]float64, xMax+1)
+	for v := 0; v < xMax; v++ {
+		P[v+1] = P[v] + float64(hist[uint8(v)])
+		S[v+1] = S[v] + float64(v*hist[uint8(v)])
+	}
+
+	// Variance term for the class holding the grey levels u to v inclusive
+	H := func(u, v int) float64 {
+		p := P[v+1] - P[u]
+		if p == 0 {
+			return math.Inf(-1)
+		}
+		s := S[v+1] - S[u]
+		return s * s / p
+	}
+
+	// best[k][v] is the largest sum for splitting the grey levels 0 to v
+	// into k+1 classes and start[k][v] is where the last of those classes starts
+	classes := n + 1
+	best := make([][]float64, classes)
+	start := make([][]int, classes)
+	for k := range best {
+		best[k] = make([]float64, xMax)
+		start[k] = make([]int, xMax)
+	}
+	for v := 0; v < xMax; v++ {
+		best[0][v] = H(0, v)
+	}
+	for k := 1; k < classes; k++ {
+		for v := 0; v < xMax; v++ {
+			best[k][v] = math.Inf(-1)
+			for u := k; u <= v; u++ {
+				if variation := best[k-1][u-1] + H(u, v); variation > best[k][v] {
+					best[k][v] = variation
+					start[k][v] = u
+				}
+			}
+		}
+	}
+
+	// Walk back through the classes to find where each one ends
+	T := make([]uint8, n)
+	v := xMax - 1
+	for k := classes - 1; k > 0; k-- {
+		v = start[k][v] - 1
+		T[k-1] = uint8(v)
+	}
+
+	return T
+}
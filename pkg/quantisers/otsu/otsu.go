@@ -9,7 +9,8 @@ import (
 const xMax = 256
 
 // Returns one greyscale colour which best represents the threshold
-// for splitting the image into black and white. Otsu only supports m = 1.
+// for splitting the image into black and white. For more colours
+// use QuantiseGreyscaleMulti
 func QuantiseGreyscale(img image.Image) color.Palette {
 	histogram := quantisers.CreateGreyscaleHistogram(img)
 	threshold := calculateThreshold(histogram)
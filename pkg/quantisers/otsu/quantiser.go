@@ -0,0 +1,34 @@
+package otsu
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "otsu", it only supports greyscale quantisation. A palette of
+// one colour is the threshold which splits the image into black and white, larger palettes
+// hold the mean of each class from QuantiseGreyscaleMulti with one less threshold than colours
+type Quantiser struct{}
+
+func (Quantiser) Name() string {
+	return "otsu"
+}
+
+func (Quantiser) QuantiseGreyscale(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	if m == 1 {
+		if T := Thresholds(img, 1); T != nil {
+			return color.Palette{color.Gray{T[0]}}, nil
+		}
+		// Images with one grey level have no threshold so the level is returned
+		return QuantiseGreyscaleMulti(img, 1), nil
+	}
+	return QuantiseGreyscaleMulti(img, m-1), nil
+}
@@ -0,0 +1,78 @@
+package otsu
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+var stripes = quantisetest.GreyStripes
+
+func TestQuantiseGreyscale(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		m    int
+		want color.Palette
+	}{
+		{"single colour", stripes(100), 1, color.Palette{color.Gray{100}}},
+		{"single colour with more colours", stripes(100), 2, color.Palette{color.Gray{100}}},
+		{"threshold", stripes(10, 200), 1, color.Palette{color.Gray{10}}},
+		{"two colours", stripes(10, 100, 200), 2, color.Palette{color.Gray{55}, color.Gray{200}}},
+		{"three colours", stripes(10, 100, 200), 3, color.Palette{color.Gray{10}, color.Gray{100}, color.Gray{200}}},
+		{"m above grey levels", stripes(10, 100, 200), 10, color.Palette{color.Gray{10}, color.Gray{100}, color.Gray{200}}},
+	}
+
+	for _, tt := range tests {
+		palette, err := Quantiser{}.QuantiseGreyscale(tt.img, tt.m)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(palette, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, palette, tt.want)
+		}
+	}
+}
+
+func TestQuantiseGreyscaleMulti(t *testing.T) {
+	// Each colour is the mean of a class, so pixels are recreated from the nearest class
+	tests := []struct {
+		name string
+		img  image.Image
+		m    int
+		want color.Palette
+	}{
+		{"single colour", stripes(100), 1, color.Palette{color.Gray{100}}},
+		{"one threshold", stripes(0, 10, 20, 200, 210, 220), 1, color.Palette{color.Gray{10}, color.Gray{210}}},
+		{"two thresholds", stripes(0, 10, 20, 100, 200, 210, 220), 2, color.Palette{color.Gray{10}, color.Gray{100}, color.Gray{210}}},
+		{"m above grey levels", stripes(10, 200), 5, color.Palette{color.Gray{10}, color.Gray{200}}},
+	}
+
+	for _, tt := range tests {
+		if got := QuantiseGreyscaleMulti(tt.img, tt.m); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuantiseGreyscaleMultiNil(t *testing.T) {
+	if palette := QuantiseGreyscaleMulti(image.NewGray(image.Rect(0, 0, 0, 0)), 1); palette != nil {
+		t.Errorf("empty image: got %v, want nil", palette)
+	}
+	if palette := QuantiseGreyscaleMulti(stripes(0, 255), 0); palette != nil {
+		t.Errorf("zero m: got %v, want nil", palette)
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	// Thresholds split the grey levels into classes so that each class is as tight as possible
+	img := stripes(0, 10, 20, 200, 210, 220)
+	if got, want := Thresholds(img, 1), []uint8{20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := Thresholds(img, 6); got != nil {
+		t.Errorf("more thresholds than grey levels: got %v, want nil", got)
+	}
+}
@@ -2,6 +2,7 @@ package quantisers
 
 import (
 	"errors"
+	"github.com/fiwippi/go-quantise/pkg/colours"
 	"image"
 	"image/color"
 	"image/draw"
@@ -13,38 +14,138 @@ var (
 	WHITE = color.Gray{Y: 255}
 )
 
+// Options for recreating an image from a palette
+type Options struct {
+	Dither    DitherType
+	Threshold ThresholdType // How the image is binarised if the palette is one greyscale colour
+	Window    int           // Side length of the window used by local thresholds, if zero then 1/8th of the image size is used
+	K         float64       // Sensitivity of local thresholds, if zero then the default for the threshold is used
+	// Metric used to find the nearest palette colour to each pixel, if nil then the
+	// RGB distance used by color.Palette is used. This should match the metric used
+	// to create the palette, e.g. colours.CIEDE2000 for palettes from pnnlab
+	Metric colours.Metric
+	// Kernel used by the CustomKernel dither type
+	Kernel *Kernel
+	// Whether error diffusion processes every other row right to left, FloydSteinbergSerpentine always does
+	Serpentine bool
+	// Strength of dithering in the range [0, 1], error diffusion only spreads this fraction of the error
+	// and ordered dithers scale their amplitude by it. Lower strengths reduce noise on smooth gradients
+	// and zero doesn't dither at all. If nil then dithering is at full strength
+	Strength *float64
+	// Texture used by the BlueNoise dither type instead of the built in 64x64 one, e.g. one from VoidAndCluster
+	Texture ThresholdMap
+	// Threshold map used by the CustomThresholdMap dither type, e.g. one from ClusteredDot
+	ThresholdMap ThresholdMap
+}
+
 // Recreates image from colour palette. If one greyscale colour is
 // specified then the image is recreated in black and white with the
 // split between them at the specified input colour
 func ImageFromPalette(img image.Image, c color.Palette, ditherType DitherType) (image.Image, error) {
+	return ImageFromPaletteWithOpts(img, c, Options{Dither: ditherType})
+}
+
+// Recreates image from colour palette using the given options. If one greyscale
+// colour is specified then the image is recreated in black and white, either with
+// the split at the specified input colour or using a local threshold
+func ImageFromPaletteWithOpts(img image.Image, c color.Palette, opts Options) (image.Image, error) {
 	if c == nil || len(c) < 1 {
-		return nil, errors.New("colour palette must be specified")
+		return nil, ErrEmptyPalette
 	}
 
-	cimg := image.NewRGBA(img.Bounds())
-	draw.Draw(cimg, img.Bounds(), img, image.Point{}, draw.Src)
-
 	// Process one colour greyscale palettes
 	if len(c) == 1 && reflect.TypeOf(c[0]) == reflect.TypeOf(color.Gray{}) {
-		return noDitherSingle(cimg, c), nil
+		cimg := image.NewRGBA(img.Bounds())
+		draw.Draw(cimg, img.Bounds(), img, image.Point{}, draw.Src)
+
+		switch opts.Threshold {
+		case GlobalThreshold:
+			return noDitherSingle(cimg, c), nil
+		case Niblack, Sauvola, Bradley:
+			return localThreshold(cimg, opts.Threshold, opts.Window, opts.K), nil
+		default:
+			return nil, errors.New("invalid threshold type")
+		}
 	}
 
+	kernel, matrix, err := opts.dither()
+	if err != nil {
+		return nil, err
+	}
+	strength, err := opts.strength()
+	if err != nil {
+		return nil, err
+	}
+	serpentine := opts.Serpentine || opts.Dither == FloydSteinbergSerpentine
+
+	// Planar images in the metric's colour space are dithered and remapped without converting their pixels
+	if points, ok := colours.PlanarPoints(img, opts.Metric); ok {
+		r := NewRemapper(c, opts.Metric)
+		switch {
+		case kernel != nil:
+			return errorDiffusionPoints(points, r, *kernel, serpentine, strength), nil
+		case matrix != nil:
+			return orderedDitherPoints(points, r, matrix, strength), nil
+		default:
+			return noDitherPoints(points, r), nil
+		}
+	}
+
+	cimg := image.NewRGBA(img.Bounds())
+	draw.Draw(cimg, img.Bounds(), img, image.Point{}, draw.Src)
+
 	// Process multi colour palettes
-	switch ditherType {
+	model := paletteModel(c, opts.Metric)
+	switch {
+	case kernel != nil:
+		return errorDiffusion(cimg, model, *kernel, serpentine, strength), nil
+	case matrix != nil:
+		return bayerDitherWithOpts(cimg, c, model, matrix, strength), nil
+	default:
+		return noDitherMulti(cimg, model), nil
+	}
+}
+
+// Returns the error diffusion kernel or the threshold map of the dither type, both are nil for NoDither
+func (opts Options) dither() (*Kernel, ThresholdMap, error) {
+	if k, ok := ditherKernels[opts.Dither]; ok {
+		return &k, nil, nil
+	}
+
+	switch opts.Dither {
 	case NoDither:
-		return noDitherMulti(cimg, c), nil
-	case FloydSteinberg:
-		return floydSteinbergDither(cimg, c), nil
-	case FloydSteinbergSerpentine:
-		return floydSteinbergSerpentineDither(cimg, c), nil
+		return nil, nil, nil
+	case CustomKernel:
+		if opts.Kernel == nil {
+			return nil, nil, errors.New("kernel must be specified for custom error diffusion")
+		}
+		if !opts.Kernel.valid() {
+			return nil, nil, errors.New("kernel must only diffuse error to unprocessed pixels and have a non-zero divisor")
+		}
+		return opts.Kernel, nil, nil
+	// The Bayer dither types have always indexed their matrix by [x][y]
 	case Bayer2x2:
-		return bayerDither2x2(cimg, c), nil
+		return nil, BayerMatrix(2).transpose(), nil
 	case Bayer4x4:
-		return bayerDither4x4(cimg, c), nil
+		return nil, BayerMatrix(4).transpose(), nil
 	case Bayer8x8:
-		return bayerDither8x8(cimg, c), nil
+		return nil, BayerMatrix(8).transpose(), nil
+	case BlueNoise:
+		texture := opts.Texture
+		if texture == nil {
+			texture = blueNoise()
+		}
+		if !texture.valid() {
+			return nil, nil, errors.New("texture must be rectangular and not empty")
+		}
+		return nil, texture, nil
+	case CustomThresholdMap:
+		if !opts.ThresholdMap.valid() {
+			return nil, nil, errors.New("threshold map must be rectangular and not empty for custom ordered dithering")
+		}
+		return nil, opts.ThresholdMap, nil
 	default:
-		return nil, errors.New("invalid dither type")
+		return nil, nil, errors.New("invalid dither type")
 	}
 }
 
@@ -61,3 +162,29 @@ func ColourPaletteImage(c color.Palette, size int) image.Image {
 
 	return img
 }
+
+// Returns the dithering strength, which is full strength if it isn't set
+func (opts Options) strength() (float64, error) {
+	if opts.Strength == nil {
+		return 1, nil
+	}
+	if s := *opts.Strength; s >= 0 && s <= 1 {
+		return s, nil
+	}
+	return 0, errors.New("dither strength must be in the range [0, 1]")
+}
+
+// Returns the model which converts colours to their nearest palette colour
+// as measured by the metric, if there is no metric the palette itself is used
+func paletteModel(c color.Palette, metric colours.Metric) color.Model {
+	if metric == nil {
+		return c
+	}
+	return NewRemapper(c, metric)
+}
+
+// Converts a colour to 8-bit RGB values
+func toRGB(c color.Color) *colours.RGB {
+	r, g, b, _ := c.RGBA()
+	return &colours.RGB{R: float64(r >> 8), G: float64(g >> 8), B: float64(b >> 8)}
+}
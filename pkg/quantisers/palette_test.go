@@ -0,0 +1,154 @@
+package quantisers
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// Returns a smooth gradient which needs dithering to be recreated from a few colours
+func gradient() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(4 * x), G: uint8(8 * y), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+var ditherTypes = map[string]DitherType{
+	"NoDither":                 NoDither,
+	"FloydSteinberg":           FloydSteinberg,
+	"FloydSteinbergSerpentine": FloydSteinbergSerpentine,
+	"Bayer2x2":                 Bayer2x2,
+	"Bayer4x4":                 Bayer4x4,
+	"Bayer8x8":                 Bayer8x8,
+	"JarvisJudiceNinke":        JarvisJudiceNinke,
+	"Stucki":                   Stucki,
+	"Burkes":                   Burkes,
+	"Sierra3":                  Sierra3,
+	"Sierra2":                  Sierra2,
+	"SierraLite":               SierraLite,
+	"Atkinson":                 Atkinson,
+	"StevensonArce":            StevensonArce,
+	"BlueNoise":                BlueNoise,
+}
+
+// Returns whether every pixel of the image is a palette colour
+func onlyPalette(img image.Image, palette color.Palette) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if palette[palette.Index(img.At(x, y))] != img.At(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestImageFromPalette(t *testing.T) {
+	img := gradient()
+	inputs := map[string]struct {
+		img    image.Image
+		metric colours.Metric
+	}{
+		"RGB":         {img, nil},
+		"CIEDE2000":   {img, colours.CIEDE2000},
+		"LAB image":   {colours.ToLABImage(img), colours.CIEDE2000},
+		"OKLab image": {colours.ToOKLabImage(img), colours.OKLabDistance},
+	}
+
+	for input, in := range inputs {
+		for name, d := range ditherTypes {
+			got, err := ImageFromPaletteWithOpts(in.img, testPalette, Options{Dither: d, Metric: in.metric})
+			if err != nil {
+				t.Errorf("%s %s: %v", input, name, err)
+				continue
+			}
+			if got.Bounds() != img.Bounds() {
+				t.Errorf("%s %s: got bounds %v, want %v", input, name, got.Bounds(), img.Bounds())
+			}
+			if !onlyPalette(got, testPalette) {
+				t.Errorf("%s %s: image has colours outside of the palette", input, name)
+			}
+		}
+	}
+}
+
+func TestImageFromPaletteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		c    color.Palette
+		opts Options
+	}{
+		{"empty palette", nil, Options{}},
+		{"invalid dither type", testPalette, Options{Dither: -1}},
+		{"custom kernel without kernel", testPalette, Options{Dither: CustomKernel}},
+		{"empty kernel", testPalette, Options{Dither: CustomKernel, Kernel: &Kernel{Divisor: 1}}},
+		{"zero divisor", testPalette, Options{Dither: CustomKernel, Kernel: &Kernel{Matrix: [][]float64{{0, 1}, {1, -2}}}}},
+		{"kernel before the current pixel", testPalette, Options{Dither: CustomKernel, Kernel: &Kernel{Matrix: [][]float64{{0, 1}}, X: -1}}},
+		{"kernel on the current pixel", testPalette, Options{Dither: CustomKernel, Kernel: &Kernel{Matrix: [][]float64{{1, 1}}}}},
+		{"kernel after its first row", testPalette, Options{Dither: CustomKernel, Kernel: &Kernel{Matrix: [][]float64{{0, 1}}, X: 2}}},
+		{"custom threshold map without map", testPalette, Options{Dither: CustomThresholdMap}},
+		{"ragged threshold map", testPalette, Options{Dither: CustomThresholdMap, ThresholdMap: ThresholdMap{{0, 1}, {2}}}},
+		{"invalid threshold type", color.Palette{color.Gray{128}}, Options{Threshold: -1}},
+	}
+
+	for _, tt := range tests {
+		if _, err := ImageFromPaletteWithOpts(gradient(), tt.c, tt.opts); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestStrength(t *testing.T) {
+	img := gradient()
+	noDither, _ := ImageFromPalette(img, testPalette, NoDither)
+	full, _ := ImageFromPalette(img, testPalette, FloydSteinberg)
+
+	tests := []struct {
+		name     string
+		strength *float64
+		want     image.Image // If nil then the image should differ from both of the others
+		err      bool
+	}{
+		{"unset", nil, full, false},
+		{"full", float(1), full, false},
+		{"none", float(0), noDither, false},
+		{"half", float(0.5), nil, false},
+		{"negative", float(-0.1), nil, true},
+		{"above one", float(1.5), nil, true},
+	}
+
+	for _, tt := range tests {
+		for name, d := range ditherTypes {
+			if d == NoDither {
+				continue
+			}
+			got, err := ImageFromPaletteWithOpts(img, testPalette, Options{Dither: d, Strength: tt.strength})
+			if (err != nil) != tt.err {
+				t.Errorf("%s %s: got error %v", tt.name, name, err)
+			}
+			if err != nil || d != FloydSteinberg && tt.want == full {
+				continue
+			}
+			switch {
+			case tt.want != nil && !equal(got, tt.want):
+				t.Errorf("%s %s: images differ", tt.name, name)
+			case tt.want == nil && (equal(got, noDither) || equal(got, full)):
+				t.Errorf("%s %s: image wasn't partially dithered", tt.name, name)
+			}
+		}
+	}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func equal(a, b image.Image) bool {
+	return string(a.(*image.RGBA).Pix) == string(b.(*image.RGBA).Pix)
+}
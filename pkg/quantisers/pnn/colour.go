@@ -2,11 +2,20 @@ package pnn
 
 import (
 	"github.com/fiwippi/go-quantise/internal/quantisers/pnn"
+	"github.com/fiwippi/go-quantise/pkg/colours"
 	"image"
 	"image/color"
 )
 
-// Returns a palette of "m" colours to best recreate the image from
+// Returns a palette of "m" colours to best recreate the image from,
+// returns nil if m is less than 1 or the image is empty
 func QuantiseColour(img image.Image, m int) color.Palette {
 	return pnn.RGB.QuantiseColour(img, m)
 }
+
+// Returns a palette of "m" colours to best recreate the image from, the metric is
+// used to find which colours are nearest to each other. Returns nil if m is less than 1
+// or the image is empty
+func QuantiseColourMetric(img image.Image, m int, metric colours.Metric) color.Palette {
+	return pnn.WithMetric(metric).QuantiseColour(img, m)
+}
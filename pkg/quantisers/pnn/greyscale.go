@@ -6,7 +6,8 @@ import (
 	"image/color"
 )
 
-// Returns "m" greyscale colours to best recreate the colour palette of the original image
+// Returns "m" greyscale colours to best recreate the colour palette of the original image,
+// returns nil if m is less than 1 or the image is empty
 func QuantiseGreyscale(img image.Image, m int) color.Palette {
 	return pnn.QuantiseGreyscale(img, m)
 }
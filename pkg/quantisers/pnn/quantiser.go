@@ -0,0 +1,39 @@
+package pnn
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "pnn", colours are compared in RGB space
+// unless a metric is given
+type Quantiser struct {
+	Metric colours.Metric // Metric used to compare colours, if nil then the RGB merge cost is used
+}
+
+func (Quantiser) Name() string {
+	return "pnn"
+}
+
+func (q Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	if q.Metric != nil {
+		return QuantiseColourMetric(img, m, q.Metric), nil
+	}
+	return QuantiseColour(img, m), nil
+}
+
+func (Quantiser) QuantiseGreyscale(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	return QuantiseGreyscale(img, m), nil
+}
@@ -0,0 +1,52 @@
+package pnn
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image/color"
+	"math"
+	"testing"
+)
+
+var red, green, blue = quantisetest.Red, quantisetest.Green, quantisetest.Blue
+
+func TestQuantiseColourMetric(t *testing.T) {
+	// Clustering with a metric is capped at the number of colours in the image too
+	img := quantisetest.Stripes(red, green, blue)
+	for _, metric := range []colours.Metric{colours.OKLabDistance, colours.CIEDE2000, colours.Redmean} {
+		palette, err := Quantiser{Metric: metric}.QuantiseColour(img, math.MaxInt32)
+		if err != nil || len(palette) != 3 {
+			t.Errorf("%T: got %d colours and error %v, want 3", metric, len(palette), err)
+		}
+	}
+}
+
+func TestQuantiseColourExact(t *testing.T) {
+	// With enough colours each one is returned unchanged, planar images are binned
+	// by their points but their bins are converted back to the same colours
+	img := quantisetest.Stripes(red, green, blue)
+	palettes := map[string]color.Palette{
+		"RGB":   QuantiseColour(img, 3),
+		"LAB":   QuantiseColourMetric(colours.ToLABImage(img), 3, colours.CIEDE2000),
+		"OKLab": QuantiseColourMetric(colours.ToOKLabImage(img), 3, colours.OKLabDistance),
+	}
+	for name, palette := range palettes {
+		for _, want := range []color.Color{red, green, blue} {
+			if !contains(palette, want) {
+				t.Errorf("%s: %v missing from palette %v", name, want, palette)
+			}
+		}
+	}
+}
+
+// Returns whether the palette has the colour, comparing their 8-bit values
+func contains(palette color.Palette, want color.Color) bool {
+	for _, c := range palette {
+		r1, g1, b1, a1 := c.RGBA()
+		r2, g2, b2, a2 := want.RGBA()
+		if r1>>8 == r2>>8 && g1>>8 == g2>>8 && b1>>8 == b2>>8 && a1>>8 == a2>>8 {
+			return true
+		}
+	}
+	return false
+}
@@ -2,11 +2,25 @@ package pnnlab
 
 import (
 	"github.com/fiwippi/go-quantise/internal/quantisers/pnn"
+	"github.com/fiwippi/go-quantise/pkg/colours"
 	"image"
 	"image/color"
 )
 
-// Returns a palette of "m" colours to best recreate the image from
+// Returns a palette of "m" colours to best recreate the image from,
+// returns nil if m is less than 1 or the image is empty
 func QuantiseColour(img image.Image, m int) color.Palette {
 	return pnn.LAB.QuantiseColour(img, m)
 }
+
+// Returns a palette of "m" colours to best recreate an image whose colours are in the
+// working space, e.g. colours.DisplayP3, the palette is in the same working space
+func QuantiseColourSpace(img image.Image, m int, ws *colours.WorkingSpace) color.Palette {
+	return pnn.WithMetric(colours.MetricInSpace(colours.CIEDE2000, ws)).QuantiseColour(img, m)
+}
+
+// Returns the palette earlier versions of this package created, they converted colours
+// to LAB incorrectly, so this should only be used to reproduce existing palettes
+func QuantiseColourLegacy(img image.Image, m int) color.Palette {
+	return pnn.LegacyLAB.QuantiseColour(img, m)
+}
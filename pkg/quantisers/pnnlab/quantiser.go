@@ -0,0 +1,39 @@
+package pnnlab
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "pnnlab", colours are compared in LAB space
+type Quantiser struct {
+	Legacy bool                  // Whether to use the LAB conversion of earlier versions, see QuantiseColourLegacy
+	Space  *colours.WorkingSpace // Working space of the images' colours, if nil then sRGB is used
+}
+
+func (Quantiser) Name() string {
+	return "pnnlab"
+}
+
+func (q Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	if q.Legacy {
+		return QuantiseColourLegacy(img, m), nil
+	}
+	return QuantiseColourSpace(img, m, q.Space), nil
+}
+
+func (Quantiser) QuantiseGreyscale(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	return QuantiseGreyscale(img, m), nil
+}
@@ -0,0 +1,70 @@
+package pnnlab
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestQuantiseColourLegacy(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(20 + 30*x), G: uint8(200 - 20*y), B: uint8(40 * ((x + y) % 4)), A: 255})
+		}
+	}
+
+	// Palettes created by pnnlab.QuantiseColour before the LAB conversion was corrected
+	tests := []struct {
+		m    int
+		want color.Palette
+	}{
+		{2, color.Palette{
+			color.RGBA{R: 0x94, G: 0x52, B: 0x61, A: 0xff},
+			color.RGBA{R: 0x76, G: 0x8f, B: 0x31, A: 0xff},
+		}},
+		{4, color.Palette{
+			color.RGBA{R: 0x41, G: 0x5a, B: 0x78, A: 0xff},
+			color.RGBA{R: 0x50, G: 0xa0, B: 0x39, A: 0xff},
+			color.RGBA{R: 0xa7, G: 0x79, B: 0x28, A: 0xff},
+			color.RGBA{R: 0xb6, G: 0x50, B: 0x58, A: 0xff},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := QuantiseColourLegacy(img, tt.m); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d: got %v, want %v", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestDominantPlanar(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(4 * x), G: uint8(100 + 8*y), B: 80, A: 255})
+		}
+	}
+
+	// The error within the bins of a planar image is estimated, so it's reported even when no
+	// bins are merged, although it differs from the RGB error since the bins aren't the same
+	for _, m := range []int{1, 4096} {
+		want, _ := Dominant(img, m)
+		got, _ := Dominant(colours.ToLABImage(img), m)
+		if e, w := meanError(got), meanError(want); e == 0 || e < w/2 || e > 2*w {
+			t.Errorf("%d: got an error of %v, want %v", m, e, w)
+		}
+	}
+}
+
+// Returns the mean error of the whole image represented by the swatches
+func meanError(swatches []quantisers.Swatch) float64 {
+	var e float64
+	for _, s := range swatches {
+		e += s.MeanError * s.Fraction
+	}
+	return e
+}
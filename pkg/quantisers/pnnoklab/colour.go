@@ -0,0 +1,20 @@
+package pnnoklab
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisers/pnn"
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image"
+	"image/color"
+)
+
+// Returns a palette of "m" colours to best recreate the image from,
+// returns nil if m is less than 1 or the image is empty
+func QuantiseColour(img image.Image, m int) color.Palette {
+	return pnn.OKLab.QuantiseColour(img, m)
+}
+
+// Returns a palette of "m" colours to best recreate an image whose colours are in the
+// working space, e.g. colours.DisplayP3, the palette is in the same working space
+func QuantiseColourSpace(img image.Image, m int, ws *colours.WorkingSpace) color.Palette {
+	return pnn.WithMetric(colours.MetricInSpace(colours.OKLabDistance, ws)).QuantiseColour(img, m)
+}
@@ -0,0 +1,16 @@
+package pnnoklab
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisers/pnn"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+)
+
+// Returns the "m" dominant colours of the image sorted by prominence, each
+// colour is returned with the number and fraction of pixels it represents
+func Dominant(img image.Image, m int) ([]quantisers.Swatch, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	return pnn.OKLab.Dominant(img, m), nil
+}
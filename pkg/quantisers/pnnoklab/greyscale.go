@@ -0,0 +1,13 @@
+package pnnoklab
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisers/pnn"
+	"image"
+	"image/color"
+)
+
+// Returns "m" greyscale colours to best recreate the colour palette of the original image,
+// returns nil if m is less than 1 or the image is empty
+func QuantiseGreyscale(img image.Image, m int) color.Palette {
+	return pnn.QuantiseGreyscale(img, m)
+}
@@ -0,0 +1,35 @@
+package pnnoklab
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "pnnoklab", colours are compared in OKLab space
+type Quantiser struct {
+	Space *colours.WorkingSpace // Working space of the images' colours, if nil then sRGB is used
+}
+
+func (Quantiser) Name() string {
+	return "pnnoklab"
+}
+
+func (q Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	return QuantiseColourSpace(img, m, q.Space), nil
+}
+
+func (Quantiser) QuantiseGreyscale(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	return QuantiseGreyscale(img, m), nil
+}
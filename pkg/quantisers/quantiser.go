@@ -0,0 +1,100 @@
+package quantisers
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"sync"
+)
+
+// Quantiser is an algorithm which reduces an image to a palette of colours,
+// every Quantiser must implement at least one of ColourQuantiser or
+// GreyscaleQuantiser, which can be checked with a type assertion
+type Quantiser interface {
+	// Name the quantiser is registered under
+	Name() string
+}
+
+// Quantiser which can create a palette of "m" colours
+type ColourQuantiser interface {
+	Quantiser
+	QuantiseColour(img image.Image, m int) (color.Palette, error)
+}
+
+// Quantiser which can create a palette of "m" greyscale colours
+type GreyscaleQuantiser interface {
+	Quantiser
+	QuantiseGreyscale(img image.Image, m int) (color.Palette, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Quantiser)
+)
+
+// Makes a quantiser available by its name. Register panics if it is
+// called twice with the same name or if the quantiser implements
+// neither ColourQuantiser nor GreyscaleQuantiser
+func Register(q Quantiser) {
+	_, colour := q.(ColourQuantiser)
+	_, greyscale := q.(GreyscaleQuantiser)
+	if !colour && !greyscale {
+		panic("quantisers: Register quantiser " + q.Name() + " supports no quantisation")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[q.Name()]; dup {
+		panic("quantisers: Register called twice for quantiser " + q.Name())
+	}
+	registry[q.Name()] = q
+}
+
+// Returns the quantiser registered under the given name
+func Lookup(name string) (Quantiser, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	q, ok := registry[name]
+	if !ok {
+		return nil, ErrUnknownQuantiser
+	}
+	return q, nil
+}
+
+// Returns the sorted names of all registered quantisers
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Quantises the image into "m" colours using the quantiser registered under the given name
+func QuantiseColour(name string, img image.Image, m int) (color.Palette, error) {
+	q, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	cq, ok := q.(ColourQuantiser)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	return cq.QuantiseColour(img, m)
+}
+
+// Quantises the image into "m" greyscale colours using the quantiser registered under the given name
+func QuantiseGreyscale(name string, img image.Image, m int) (color.Palette, error) {
+	q, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	gq, ok := q.(GreyscaleQuantiser)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	return gq.QuantiseGreyscale(img, m)
+}
@@ -0,0 +1,120 @@
+package quantisers_test
+
+import (
+	"errors"
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/kapur"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/kittler"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/lmq"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/mediancut"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/neuquant"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/octree"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/otsu"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/pnn"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/pnnlab"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/pnnoklab"
+	_ "github.com/fiwippi/go-quantise/pkg/quantisers/wu"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// Quantiser which supports no quantisation
+type nameOnly struct{}
+
+func (nameOnly) Name() string {
+	return "name-only"
+}
+
+// Returns whether the function panics
+func panics(f func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	f()
+	return false
+}
+
+func TestRegister(t *testing.T) {
+	if !panics(func() { quantisers.Register(otsu.Quantiser{}) }) {
+		t.Error("registering a name twice didn't panic")
+	}
+	if !panics(func() { quantisers.Register(nameOnly{}) }) {
+		t.Error("registering a quantiser which supports no quantisation didn't panic")
+	}
+	if _, err := quantisers.Lookup("name-only"); !errors.Is(err, quantisers.ErrUnknownQuantiser) {
+		t.Errorf("got error %v for a quantiser which failed to register", err)
+	}
+}
+
+func TestNames(t *testing.T) {
+	want := []string{"kapur", "kittler", "lmq", "mediancut", "neuquant", "octree", "otsu", "pnn", "pnnlab", "pnnoklab", "wu"}
+	if got := quantisers.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, name := range want {
+		if q, err := quantisers.Lookup(name); err != nil || q.Name() != name {
+			t.Errorf("%s: got %v and error %v", name, q, err)
+		}
+	}
+}
+
+func TestQuantiseByName(t *testing.T) {
+	img := quantisetest.Stripes(quantisetest.Red, quantisetest.Blue)
+	tests := []struct {
+		name      string
+		quantiser string
+		greyscale bool
+		err       error
+	}{
+		{"unknown colour", "missing", false, quantisers.ErrUnknownQuantiser},
+		{"unknown greyscale", "missing", true, quantisers.ErrUnknownQuantiser},
+		{"greyscale only", "otsu", false, quantisers.ErrUnsupported},
+		{"colour only", "wu", true, quantisers.ErrUnsupported},
+		{"colour", "wu", false, nil},
+		{"greyscale", "otsu", true, nil},
+	}
+
+	for _, tt := range tests {
+		var err error
+		if tt.greyscale {
+			_, err = quantisers.QuantiseGreyscale(tt.quantiser, img, 2)
+		} else {
+			_, err = quantisers.QuantiseColour(tt.quantiser, img, 2)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestEdgeCases(t *testing.T) {
+	for _, name := range quantisers.Names() {
+		q, _ := quantisers.Lookup(name)
+		if cq, ok := q.(quantisers.ColourQuantiser); ok {
+			checkEdgeCases(t, name, quantisetest.EdgeCases(false), cq.QuantiseColour)
+		}
+		if gq, ok := q.(quantisers.GreyscaleQuantiser); ok {
+			checkEdgeCases(t, name, quantisetest.EdgeCases(true), gq.QuantiseGreyscale)
+		}
+	}
+}
+
+func checkEdgeCases(t *testing.T, name string, cases []quantisetest.EdgeCase, quantise func(image.Image, int) (color.Palette, error)) {
+	for _, tt := range cases {
+		palette, err := quantise(tt.Img, tt.M)
+
+		// Kapur and Kittler only support one colour
+		if errors.Is(err, quantisers.ErrUnsupportedPaletteSize) && tt.M > 1 {
+			continue
+		}
+		if !errors.Is(err, tt.Err) {
+			t.Errorf("%s, %s: got error %v, want %v", name, tt.Name, err, tt.Err)
+		}
+		if len(palette) != tt.Want {
+			t.Errorf("%s, %s: got %d colours, want %d", name, tt.Name, len(palette), tt.Want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package quantisers
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image/color"
+	"math"
+)
+
+// The remapper's cache holds 1<<remapCacheBits entries
+const remapCacheBits = 18
+
+// Cached nearest palette index of a colour
+type remapEntry struct {
+	key   uint32 // RGB values of the colour packed as 0x01RRGGBB, zero if the entry is empty
+	index int
+}
+
+// Cached nearest palette index of a point
+type pointEntry struct {
+	key   [3]float32
+	set   bool
+	index int
+}
+
+// Remapper converts colours to their nearest palette colour as measured by a metric,
+// so an image can be recreated using the metric which was used to create its palette.
+// The palette is converted by the metric once when the remapper is created and the
+// nearest palette colour of recently seen colours is cached, so even slow metrics such
+// as CIEDE2000 stay fast. Alpha is ignored. A Remapper isn't safe for concurrent use
+type Remapper struct {
+	palette color.Palette
+	metric  colours.Metric
+	points  []colours.Point
+	cache   []remapEntry // Only created if there is a metric
+	pcache  []pointEntry // Only created once points are remapped
+}
+
+// Creates a remapper for the palette, if the metric is nil then the
+// RGB distance used by color.Palette is used
+func NewRemapper(c color.Palette, metric colours.Metric) *Remapper {
+	r := &Remapper{
+		palette: c,
+		metric:  metric,
+	}
+	if metric != nil {
+		r.cache = make([]remapEntry, 1<<remapCacheBits)
+		r.points = make([]colours.Point, len(c))
+		for i := range c {
+			r.points[i] = metric.Convert(toRGB(c[i]))
+		}
+	}
+
+	return r
+}
+
+// Returns the index of the palette colour nearest to the colour
+func (r *Remapper) Index(c color.Color) int {
+	if r.metric == nil {
+		return r.palette.Index(c)
+	}
+
+	cr, cg, cb, _ := c.RGBA()
+	key := 1<<24 | (cr>>8)<<16 | (cg>>8)<<8 | cb>>8
+	entry := &r.cache[hash(key)]
+	if entry.key == key {
+		return entry.index
+	}
+
+	p := r.metric.Convert(&colours.RGB{R: float64(cr >> 8), G: float64(cg >> 8), B: float64(cb >> 8)})
+	entry.key, entry.index = key, r.nearest(p)
+	return entry.index
+}
+
+// Returns the index of the palette colour nearest to the point, the point must be in
+// the metric's colour space, such as the pixels of a planar image in that space.
+// If the remapper has no metric then the point is treated as an RGB colour
+func (r *Remapper) IndexPoint(p colours.Point) int {
+	if r.metric == nil {
+		return r.palette.Index(colours.RGB{R: p[0], G: p[1], B: p[2]})
+	}
+
+	if r.pcache == nil {
+		r.pcache = make([]pointEntry, 1<<remapCacheBits)
+	}
+	key := [3]float32{float32(p[0]), float32(p[1]), float32(p[2])}
+	entry := &r.pcache[hash(math.Float32bits(key[0])^math.Float32bits(key[1])*31^math.Float32bits(key[2])*961)]
+	if entry.set && entry.key == key {
+		return entry.index
+	}
+
+	entry.key, entry.set, entry.index = key, true, r.nearest(p)
+	return entry.index
+}
+
+// Returns the index of the palette point nearest to the point
+func (r *Remapper) nearest(p colours.Point) int {
+	nearest, dst := 0, math.MaxFloat64
+	for i := range r.points {
+		if d := r.metric.Distance(p, r.points[i]); d < dst {
+			nearest, dst = i, d
+		}
+	}
+	return nearest
+}
+
+// Returns the palette colour nearest to the colour, this makes
+// the Remapper a color.Model which maps colours onto the palette
+func (r *Remapper) Convert(c color.Color) color.Color {
+	if len(r.palette) == 0 {
+		return nil
+	}
+	return r.palette[r.Index(c)]
+}
+
+// Fibonacci hashing of the key into an index of the cache
+func hash(key uint32) uint32 {
+	return (key * 2654435769) >> (32 - remapCacheBits)
+}
@@ -0,0 +1,75 @@
+package quantisers
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/colours"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var testPalette = color.Palette{
+	color.RGBA{0, 0, 0, 255},
+	color.RGBA{255, 255, 255, 255},
+	color.RGBA{200, 30, 60, 255},
+	color.RGBA{40, 160, 70, 255},
+	color.RGBA{30, 60, 200, 255},
+	color.RGBA{230, 200, 40, 255},
+	color.RGBA{128, 128, 128, 255},
+}
+
+// Returns the index of the nearest palette colour by comparing against every palette colour
+func bruteForceIndex(c color.Color, palette color.Palette, metric colours.Metric) int {
+	p := metric.Convert(toRGB(c))
+	nearest, dst := 0, math.MaxFloat64
+	for i := range palette {
+		if d := metric.Distance(p, metric.Convert(toRGB(palette[i]))); d < dst {
+			nearest, dst = i, d
+		}
+	}
+	return nearest
+}
+
+func TestRemapper(t *testing.T) {
+	metrics := map[string]colours.Metric{
+		"CIE76":     colours.CIE76,
+		"CIE94":     colours.CIE94,
+		"CIEDE2000": colours.CIEDE2000,
+		"CMC":       colours.CMC(2, 1),
+		"Redmean":   colours.Redmean,
+		"OKLab":     colours.OKLabDistance,
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for name, metric := range metrics {
+		r := NewRemapper(testPalette, metric)
+		for i := 0; i < 2000; i++ {
+			c := color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255}
+			want := bruteForceIndex(c, testPalette, metric)
+			// Asking twice checks the cached answer as well
+			for j := 0; j < 2; j++ {
+				if got := r.Index(c); got != want {
+					t.Errorf("%s: %v got index %d, want %d", name, c, got, want)
+				}
+			}
+			if got := r.IndexPoint(metric.Convert(toRGB(c))); got != want {
+				t.Errorf("%s: point of %v got index %d, want %d", name, c, got, want)
+			}
+		}
+	}
+}
+
+func TestRemapperNoMetric(t *testing.T) {
+	r := NewRemapper(testPalette, nil)
+	if r.cache != nil {
+		t.Errorf("cache created without a metric")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		c := color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255}
+		if got, want := r.Convert(c), testPalette.Convert(c); got != want {
+			t.Errorf("%v: got %v, want %v", c, got, want)
+		}
+	}
+}
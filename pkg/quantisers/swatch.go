@@ -0,0 +1,84 @@
+package quantisers
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// A colour from a palette along with how much of the image it represents
+type Swatch struct {
+	Colour    color.Color
+	Count     int     // Number of pixels represented by the colour
+	Fraction  float64 // Fraction of the image's pixels represented by the colour, in the range 0-1
+	MeanError float64 // Mean squared error of the 8-bit RGBA values of the pixels from the colour
+}
+
+// Sorts swatches so the most prominent colour comes first, i.e.
+// the primary colour, then the secondary colour and so on
+func SortSwatches(s []Swatch) {
+	sort.SliceStable(s, func(i, j int) bool {
+		return s[i].Count > s[j].Count
+	})
+}
+
+// Quantises the image into "m" colours and returns them as swatches sorted by prominence
+func Dominant(img image.Image, q ColourQuantiser, m int) ([]Swatch, error) {
+	c, err := q.QuantiseColour(img, m)
+	if err != nil {
+		return nil, err
+	}
+	return Swatches(img, c)
+}
+
+// Returns a swatch for every colour in the palette, each pixel of the image is
+// represented by the palette colour nearest to it. The swatches are sorted
+// by prominence so unused palette colours come last
+func Swatches(img image.Image, c color.Palette) ([]Swatch, error) {
+	if len(c) < 1 {
+		return nil, ErrEmptyPalette
+	}
+	if img == nil || img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	swatches := make([]Swatch, len(c))
+	for i := range c {
+		swatches[i].Colour = c[i]
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Max.X, bounds.Max.Y
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			pixel := img.At(x, y)
+			s := &swatches[c.Index(pixel)]
+			s.Count++
+			s.MeanError += squaredError(pixel, s.Colour)
+		}
+	}
+
+	total := float64(bounds.Dx() * bounds.Dy())
+	for i := range swatches {
+		if swatches[i].Count > 0 {
+			swatches[i].MeanError /= float64(swatches[i].Count)
+		}
+		swatches[i].Fraction = float64(swatches[i].Count) / total
+	}
+	SortSwatches(swatches)
+
+	return swatches, nil
+}
+
+// Squared error between the 8-bit RGBA values of two colours
+func squaredError(c1, c2 color.Color) float64 {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+
+	dr := float64(r1>>8) - float64(r2>>8)
+	dg := float64(g1>>8) - float64(g2>>8)
+	db := float64(b1>>8) - float64(b2>>8)
+	da := float64(a1>>8) - float64(a2>>8)
+
+	return dr*dr + dg*dg + db*db + da*da
+}
@@ -0,0 +1,89 @@
+package quantisers_test
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/mediancut"
+	"github.com/fiwippi/go-quantise/pkg/quantisers/pnn"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestSwatches(t *testing.T) {
+	// Six stripes of red, three of green and one of dark green, each of 16 pixels
+	red, green, darkGreen := quantisetest.Red, quantisetest.Green, color.RGBA{G: 200, A: 255}
+	img := quantisetest.Stripes(red, red, red, red, red, red, green, green, green, darkGreen)
+
+	type want struct {
+		colour    color.Color
+		count     int
+		meanError float64
+	}
+	tests := []struct {
+		name     string
+		swatches func() ([]quantisers.Swatch, error)
+		want     []want
+	}{
+		{
+			"pnn",
+			func() ([]quantisers.Swatch, error) { return pnn.Dominant(img, 3) },
+			[]want{{red, 96, 0}, {green, 48, 0}, {darkGreen, 16, 0}},
+		},
+		{
+			"pnn merged",
+			func() ([]quantisers.Swatch, error) { return pnn.Dominant(img, 2) },
+			[]want{{red, 96, 0}, {color.RGBA{G: 241, A: 255}, 64, 567.19}},
+		},
+		{
+			"quantiser",
+			func() ([]quantisers.Swatch, error) { return quantisers.Dominant(img, mediancut.Quantiser{}, 3) },
+			[]want{{red, 96, 0}, {green, 48, 0}, {darkGreen, 16, 0}},
+		},
+		{
+			"palette",
+			func() ([]quantisers.Swatch, error) {
+				return quantisers.Swatches(img, color.Palette{quantisetest.Blue, green, red})
+			},
+			[]want{{red, 96, 0}, {green, 64, 756.25}, {quantisetest.Blue, 0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		swatches, err := tt.swatches()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(swatches) != len(tt.want) {
+			t.Fatalf("%s: got %d swatches, want %d", tt.name, len(swatches), len(tt.want))
+		}
+
+		var fractions float64
+		for i, s := range swatches {
+			w := tt.want[i]
+			if i > 0 && s.Count > swatches[i-1].Count {
+				t.Errorf("%s: swatch %d is more prominent than the one before it", tt.name, i)
+			}
+			if s.Colour != w.colour || s.Count != w.count || math.Abs(s.MeanError-w.meanError) > 0.1 {
+				t.Errorf("%s: got swatch %d %v, want %v", tt.name, i, s, w)
+			}
+			if s.Fraction != float64(s.Count)/160 {
+				t.Errorf("%s: swatch %d has fraction %v of %d pixels", tt.name, i, s.Fraction, s.Count)
+			}
+			fractions += s.Fraction
+		}
+		if math.Abs(fractions-1) > 1e-9 {
+			t.Errorf("%s: fractions sum to %v", tt.name, fractions)
+		}
+	}
+}
+
+func TestSwatchesErrors(t *testing.T) {
+	img := quantisetest.Stripes(quantisetest.Red)
+	if _, err := quantisers.Swatches(img, nil); err != quantisers.ErrEmptyPalette {
+		t.Errorf("got error %v, want %v", err, quantisers.ErrEmptyPalette)
+	}
+	if _, err := quantisers.Dominant(img, mediancut.Quantiser{}, 0); err != quantisers.ErrInvalidPaletteSize {
+		t.Errorf("got error %v, want %v", err, quantisers.ErrInvalidPaletteSize)
+	}
+}
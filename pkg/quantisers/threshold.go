@@ -0,0 +1,124 @@
+package quantisers
+
+import (
+	"image"
+	"math"
+)
+
+// Method used to recreate images from a palette of one greyscale colour
+type ThresholdType int
+
+const (
+	// The palette colour is the threshold for every pixel
+	GlobalThreshold ThresholdType = iota
+	// Threshold is mean + k * standard deviation of the window, k defaults to -0.2
+	Niblack
+	// Threshold is mean * (1 + k * (standard deviation / 128 - 1)) of the window, k defaults to 0.5
+	Sauvola
+	// Threshold is mean * (1 - k) of the window, k defaults to 0.15
+	Bradley
+)
+
+// Default sensitivities of the local thresholds
+const (
+	niblackK = -0.2
+	sauvolaK = 0.5
+	sauvolaR = 128
+	bradleyK = 0.15
+)
+
+// Local thresholding, each pixel is compared against a threshold calculated from the
+// window of pixels surrounding it. This binarises unevenly lit images much better
+// than a global threshold. The palette colour isn't used
+func localThreshold(cimg *image.RGBA, threshold ThresholdType, window int, k float64) *image.RGBA {
+	bounds := cimg.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if window <= 0 {
+		window = width
+		if height < window {
+			window = height
+		}
+		window /= 8
+	}
+	if window < 3 {
+		window = 3
+	}
+	if k == 0 {
+		switch threshold {
+		case Niblack:
+			k = niblackK
+		case Sauvola:
+			k = sauvolaK
+		case Bradley:
+			k = bradleyK
+		}
+	}
+
+	// Integral images of the grey levels and their squares, each entry holds
+	// the sum of the pixels above and to the left of it so they have an
+	// extra row and column of zeroes at the start
+	grey := make([]float64, width*height)
+	sum := make([]float64, (width+1)*(height+1))
+	sqr := make([]float64, (width+1)*(height+1))
+	for y := 0; y < height; y++ {
+		var rowSum, rowSqr float64
+		for x := 0; x < width; x++ {
+			r, g, b, _ := cimg.At(x+bounds.Min.X, y+bounds.Min.Y).RGBA()
+			r, g, b = r>>8, g>>8, b>>8
+			level := float64(uint8(0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)))
+
+			grey[y*width+x] = level
+			rowSum += level
+			rowSqr += level * level
+			sum[(y+1)*(width+1)+x+1] = sum[y*(width+1)+x+1] + rowSum
+			sqr[(y+1)*(width+1)+x+1] = sqr[y*(width+1)+x+1] + rowSqr
+		}
+	}
+
+	half := window / 2
+	for y := 0; y < height; y++ {
+		y0, y1 := maxInt(y-half, 0), minInt(y+half+1, height)
+		for x := 0; x < width; x++ {
+			x0, x1 := maxInt(x-half, 0), minInt(x+half+1, width)
+
+			area := float64((x1 - x0) * (y1 - y0))
+			s := sum[y1*(width+1)+x1] - sum[y0*(width+1)+x1] - sum[y1*(width+1)+x0] + sum[y0*(width+1)+x0]
+			s2 := sqr[y1*(width+1)+x1] - sqr[y0*(width+1)+x1] - sqr[y1*(width+1)+x0] + sqr[y0*(width+1)+x0]
+			mean := s / area
+			deviation := math.Sqrt(math.Max(s2/area-mean*mean, 0))
+
+			var T float64
+			switch threshold {
+			case Niblack:
+				T = mean + k*deviation
+			case Sauvola:
+				T = mean * (1 + k*(deviation/sauvolaR-1))
+			case Bradley:
+				T = mean * (1 - k)
+			}
+
+			if grey[y*width+x] <= T {
+				cimg.Set(x+bounds.Min.X, y+bounds.Min.Y, BLACK)
+			} else {
+				cimg.Set(x+bounds.Min.X, y+bounds.Min.Y, WHITE)
+			}
+		}
+	}
+
+	return cimg
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
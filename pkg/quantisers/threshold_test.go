@@ -0,0 +1,88 @@
+package quantisers
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// Returns a page of dark dots lit from the right, and whether each pixel is a dot
+func document() (*image.Gray, [][]bool) {
+	img := image.NewGray(image.Rect(0, 0, 96, 48))
+	text := make([][]bool, 48)
+	for y := range text {
+		text[y] = make([]bool, 96)
+		for x := range text[y] {
+			paper := 60 + 160*x/95
+			if x%6 < 2 && y%6 < 2 {
+				text[y][x] = true
+				paper /= 4
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(paper)})
+		}
+	}
+	return img, text
+}
+
+// Returns the fraction of pixels which are black where there is text and white elsewhere
+func accuracy(img image.Image, text [][]bool) float64 {
+	correct := 0
+	for y := range text {
+		for x := range text[y] {
+			if (color.GrayModel.Convert(img.At(x, y)) == BLACK) == text[y][x] {
+				correct++
+			}
+		}
+	}
+	return float64(correct) / float64(len(text)*len(text[0]))
+}
+
+func TestLocalThreshold(t *testing.T) {
+	img, text := document()
+
+	// The left of the page is darker than the dots on the right
+	global, err := ImageFromPalette(img, color.Palette{color.Gray{Y: 100}}, NoDither)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := accuracy(global, text); a > 0.9 {
+		t.Fatalf("global threshold is %.2f accurate, the page is lit too evenly", a)
+	}
+
+	for name, threshold := range map[string]ThresholdType{"Niblack": Niblack, "Sauvola": Sauvola, "Bradley": Bradley} {
+		for _, window := range []int{0, 15} {
+			opts := Options{Threshold: threshold, Window: window}
+			binarised, err := ImageFromPaletteWithOpts(img, color.Palette{color.Gray{Y: 100}}, opts)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if a := accuracy(binarised, text); a < 0.95 {
+				t.Errorf("%s, window %d: only %.2f accurate", name, window, a)
+			}
+		}
+	}
+}
+
+func TestLocalThresholdUniform(t *testing.T) {
+	// Without any variance pixels equal to the threshold are black
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	got, err := ImageFromPaletteWithOpts(img, color.Palette{color.Gray{}}, Options{Threshold: Niblack})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range got.(*image.RGBA).Pix {
+		if i%4 != 3 && p != 0 {
+			t.Fatalf("got %v, want black", got.At(i/4%8, i/32))
+		}
+	}
+}
+
+func TestInvalidThreshold(t *testing.T) {
+	img, _ := document()
+	if _, err := ImageFromPaletteWithOpts(img, color.Palette{color.Gray{}}, Options{Threshold: Bradley + 1}); err == nil {
+		t.Error("expected an error for an invalid threshold type")
+	}
+}
@@ -0,0 +1,30 @@
+package wu
+
+import (
+	"github.com/fiwippi/go-quantise/pkg/quantisers"
+	"image"
+	"image/color"
+)
+
+func init() {
+	quantisers.Register(Quantiser{})
+}
+
+// Quantiser registered as "wu", it only supports colour quantisation
+type Quantiser struct {
+	Bits int // Bits per channel used for the moment tables, if zero then DefaultBits is used
+}
+
+func (Quantiser) Name() string {
+	return "wu"
+}
+
+func (q Quantiser) QuantiseColour(img image.Image, m int) (color.Palette, error) {
+	if err := quantisers.Validate(img, m); err != nil {
+		return nil, err
+	}
+	if q.Bits == 0 {
+		return QuantiseColour(img, m), nil
+	}
+	return QuantiseColourBits(img, m, q.Bits), nil
+}
@@ -0,0 +1,44 @@
+package wu
+
+import (
+	"github.com/fiwippi/go-quantise/internal/quantisetest"
+	"image/color"
+	"math"
+	"testing"
+)
+
+var redRGBA, greenRGBA, blueRGBA = quantisetest.Red, quantisetest.Green, quantisetest.Blue
+
+func TestQuantiseColourCells(t *testing.T) {
+	// Palettes are capped at the number of non-empty cells, colours sharing a cell can't be split
+	img := quantisetest.Stripes(color.RGBA{R: 100, A: 255}, color.RGBA{R: 110, A: 255})
+	tests := []struct {
+		bits int
+		want int
+	}{
+		{1, 1},
+		{5, 2},
+		{7, 2},
+	}
+
+	for _, tt := range tests {
+		palette, err := Quantiser{Bits: tt.bits}.QuantiseColour(img, math.MaxInt32)
+		if err != nil || len(palette) != tt.want {
+			t.Errorf("%d bits: got %d colours and error %v, want %d", tt.bits, len(palette), err, tt.want)
+		}
+	}
+}
+
+func TestQuantiseColourExact(t *testing.T) {
+	// Colours in different cells of the grid are kept apart and returned unchanged
+	palette := QuantiseColour(quantisetest.Stripes(redRGBA, greenRGBA, blueRGBA), 3)
+	for i, want := range []color.Color{redRGBA, greenRGBA, blueRGBA} {
+		found := false
+		for _, c := range palette {
+			found = found || c == want
+		}
+		if !found {
+			t.Errorf("%d: %v missing from palette %v", i, want, palette)
+		}
+	}
+}
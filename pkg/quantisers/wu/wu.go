@@ -0,0 +1,305 @@
+package wu
+
+import (
+	"image"
+	"image/color"
+)
+
+// Default number of bits per channel used for the moment tables, this
+// gives a 33x33x33 grid since each side has an extra leading entry of zeroes
+const DefaultBits = 5
+
+// Directions a box can be cut along
+const (
+	red = iota
+	green
+	blue
+)
+
+// Cumulative moment tables, each entry holds the sum of the pixels
+// which lie in the box from the origin of the grid up to that entry
+type moments struct {
+	side       int       // Length of each side of the grid
+	cells      int       // Number of entries in the grid which hold pixels
+	w          []float64 // Number of pixels
+	r, g, b, a []float64 // Sum of each of the channels
+	m2         []float64 // Sum of the squared magnitudes of the RGB values
+}
+
+// Box in the grid, the lower bounds are exclusive and the upper bounds inclusive
+type box struct {
+	r0, r1, g0, g1, b0, b1 int
+}
+
+// Returns a palette of "m" colours to best recreate the image from using
+// Xiaolin Wu's quantiser with a grid of the default size. If the image has less
+// than "m" colours then fewer are returned. Returns nil if m is less than 1 or the image is empty
+func QuantiseColour(img image.Image, m int) color.Palette {
+	return QuantiseColourBits(img, m, DefaultBits)
+}
+
+// Returns a palette of "m" colours to best recreate the image from using Xiaolin
+// Wu's quantiser, the moment tables have 2^bits+1 entries along each side where bits
+// is in the range 1-7. More bits give finer splits but the tables grow eightfold per bit.
+// Returns nil if m is less than 1 or the image is empty
+func QuantiseColourBits(img image.Image, m, bits int) color.Palette {
+	if m < 1 || img.Bounds().Empty() {
+		return nil
+	}
+	if bits < 1 {
+		bits = 1
+	} else if bits > 7 {
+		bits = 7
+	}
+
+	mmt := createMoments(img, bits)
+	if m > mmt.cells {
+		m = mmt.cells
+	}
+
+	// Repeatedly cut the box with the largest variance in two
+	boxes := make([]box, 1, m)
+	variances := make([]float64, 1, m)
+	boxes[0] = box{r1: mmt.side - 1, g1: mmt.side - 1, b1: mmt.side - 1}
+	variances[0] = mmt.variance(boxes[0])
+	for len(boxes) < m {
+		next := 0
+		for i := range variances {
+			if variances[i] > variances[next] {
+				next = i
+			}
+		}
+		if variances[next] <= 0 {
+			break
+		}
+
+		b, ok := mmt.cut(&boxes[next])
+		if !ok {
+			variances[next] = 0
+			continue
+		}
+		boxes = append(boxes, b)
+		variances[next] = mmt.variance(boxes[next])
+		variances = append(variances, mmt.variance(b))
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	for _, b := range boxes {
+		w := mmt.volume(b, mmt.w)
+		if w == 0 {
+			continue
+		}
+		palette = append(palette, color.RGBA{
+			R: uint8(mmt.volume(b, mmt.r)/w + 0.5),
+			G: uint8(mmt.volume(b, mmt.g)/w + 0.5),
+			B: uint8(mmt.volume(b, mmt.b)/w + 0.5),
+			A: uint8(mmt.volume(b, mmt.a)/w + 0.5),
+		})
+	}
+
+	return palette
+}
+
+// Builds the histogram of the image in the grid and then makes the moments cumulative
+func createMoments(img image.Image, bits int) *moments {
+	side := 1<<uint(bits) + 1
+	size := side * side * side
+	mmt := &moments{
+		side: side,
+		w:    make([]float64, size),
+		r:    make([]float64, size),
+		g:    make([]float64, size),
+		b:    make([]float64, size),
+		a:    make([]float64, size),
+		m2:   make([]float64, size),
+	}
+
+	shift := uint(8 - bits)
+	bounds := img.Bounds()
+	width, height := bounds.Max.X, bounds.Max.Y
+	for y := bounds.Min.Y; y < height; y++ {
+		for x := bounds.Min.X; x < width; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			r, g, b, a = r>>8, g>>8, b>>8, a>>8
+
+			i := mmt.index(int(r>>shift)+1, int(g>>shift)+1, int(b>>shift)+1)
+			if mmt.w[i] == 0 {
+				mmt.cells++
+			}
+			mmt.w[i]++
+			mmt.r[i] += float64(r)
+			mmt.g[i] += float64(g)
+			mmt.b[i] += float64(b)
+			mmt.a[i] += float64(a)
+			mmt.m2[i] += float64(r*r + g*g + b*b)
+		}
+	}
+
+	tables := [][]float64{mmt.w, mmt.r, mmt.g, mmt.b, mmt.a, mmt.m2}
+	area := make([][]float64, len(tables))
+	for t := range area {
+		area[t] = make([]float64, side)
+	}
+	line := make([]float64, len(tables))
+	for r := 1; r < side; r++ {
+		for t := range area {
+			for i := range area[t] {
+				area[t][i] = 0
+			}
+		}
+		for g := 1; g < side; g++ {
+			for t := range line {
+				line[t] = 0
+			}
+			for b := 1; b < side; b++ {
+				i := mmt.index(r, g, b)
+				for t, table := range tables {
+					line[t] += table[i]
+					area[t][b] += line[t]
+					table[i] = table[i-side*side] + area[t][b]
+				}
+			}
+		}
+	}
+
+	return mmt
+}
+
+// Index of the entry in the moment tables
+func (mmt *moments) index(r, g, b int) int {
+	return r*mmt.side*mmt.side + g*mmt.side + b
+}
+
+// Sum of the moment over the pixels in the box
+func (mmt *moments) volume(b box, m []float64) float64 {
+	return m[mmt.index(b.r1, b.g1, b.b1)] -
+		m[mmt.index(b.r1, b.g1, b.b0)] -
+		m[mmt.index(b.r1, b.g0, b.b1)] +
+		m[mmt.index(b.r1, b.g0, b.b0)] -
+		m[mmt.index(b.r0, b.g1, b.b1)] +
+		m[mmt.index(b.r0, b.g1, b.b0)] +
+		m[mmt.index(b.r0, b.g0, b.b1)] -
+		m[mmt.index(b.r0, b.g0, b.b0)]
+}
+
+// Part of the volume which doesn't depend on where the box is cut along the direction
+func (mmt *moments) bottom(b box, dir int, m []float64) float64 {
+	switch dir {
+	case red:
+		return -m[mmt.index(b.r0, b.g1, b.b1)] +
+			m[mmt.index(b.r0, b.g1, b.b0)] +
+			m[mmt.index(b.r0, b.g0, b.b1)] -
+			m[mmt.index(b.r0, b.g0, b.b0)]
+	case green:
+		return -m[mmt.index(b.r1, b.g0, b.b1)] +
+			m[mmt.index(b.r1, b.g0, b.b0)] +
+			m[mmt.index(b.r0, b.g0, b.b1)] -
+			m[mmt.index(b.r0, b.g0, b.b0)]
+	default:
+		return -m[mmt.index(b.r1, b.g1, b.b0)] +
+			m[mmt.index(b.r1, b.g0, b.b0)] +
+			m[mmt.index(b.r0, b.g1, b.b0)] -
+			m[mmt.index(b.r0, b.g0, b.b0)]
+	}
+}
+
+// Part of the volume which depends on the position the box is cut at along the direction
+func (mmt *moments) top(b box, dir, pos int, m []float64) float64 {
+	switch dir {
+	case red:
+		return m[mmt.index(pos, b.g1, b.b1)] -
+			m[mmt.index(pos, b.g1, b.b0)] -
+			m[mmt.index(pos, b.g0, b.b1)] +
+			m[mmt.index(pos, b.g0, b.b0)]
+	case green:
+		return m[mmt.index(b.r1, pos, b.b1)] -
+			m[mmt.index(b.r1, pos, b.b0)] -
+			m[mmt.index(b.r0, pos, b.b1)] +
+			m[mmt.index(b.r0, pos, b.b0)]
+	default:
+		return m[mmt.index(b.r1, b.g1, pos)] -
+			m[mmt.index(b.r1, b.g0, pos)] -
+			m[mmt.index(b.r0, b.g1, pos)] +
+			m[mmt.index(b.r0, b.g0, pos)]
+	}
+}
+
+// Weighted variance of the pixels in the box, i.e. its sum of squared errors
+func (mmt *moments) variance(b box) float64 {
+	w := mmt.volume(b, mmt.w)
+	if w == 0 {
+		return 0
+	}
+	dr := mmt.volume(b, mmt.r)
+	dg := mmt.volume(b, mmt.g)
+	db := mmt.volume(b, mmt.b)
+	xx := mmt.volume(b, mmt.m2)
+
+	return xx - (dr*dr+dg*dg+db*db)/w
+}
+
+// Finds the position to cut the box along the direction which minimises the sum of
+// the variances of the two new boxes, returns -1 as the position if it can't be cut
+func (mmt *moments) maximise(b box, dir, first, last int, whole [4]float64) (float64, int) {
+	baseR := mmt.bottom(b, dir, mmt.r)
+	baseG := mmt.bottom(b, dir, mmt.g)
+	baseB := mmt.bottom(b, dir, mmt.b)
+	baseW := mmt.bottom(b, dir, mmt.w)
+
+	max, cut := 0.0, -1
+	for i := first; i < last; i++ {
+		halfR := baseR + mmt.top(b, dir, i, mmt.r)
+		halfG := baseG + mmt.top(b, dir, i, mmt.g)
+		halfB := baseB + mmt.top(b, dir, i, mmt.b)
+		halfW := baseW + mmt.top(b, dir, i, mmt.w)
+		if halfW == 0 {
+			continue
+		}
+		temp := (halfR*halfR + halfG*halfG + halfB*halfB) / halfW
+
+		halfR = whole[0] - halfR
+		halfG = whole[1] - halfG
+		halfB = whole[2] - halfB
+		halfW = whole[3] - halfW
+		if halfW == 0 {
+			continue
+		}
+		temp += (halfR*halfR + halfG*halfG + halfB*halfB) / halfW
+
+		if temp > max {
+			max, cut = temp, i
+		}
+	}
+
+	return max, cut
+}
+
+// Cuts the box in two, it is shrunk to become the first half and the second half
+// is returned. Returns false if the box can't be cut
+func (mmt *moments) cut(b *box) (box, bool) {
+	whole := [4]float64{
+		mmt.volume(*b, mmt.r),
+		mmt.volume(*b, mmt.g),
+		mmt.volume(*b, mmt.b),
+		mmt.volume(*b, mmt.w),
+	}
+
+	maxR, cutR := mmt.maximise(*b, red, b.r0+1, b.r1, whole)
+	maxG, cutG := mmt.maximise(*b, green, b.g0+1, b.g1, whole)
+	maxB, cutB := mmt.maximise(*b, blue, b.b0+1, b.b1, whole)
+
+	next := *b
+	switch {
+	case maxR >= maxG && maxR >= maxB:
+		if cutR < 0 {
+			return box{}, false
+		}
+		next.r0, b.r1 = cutR, cutR
+	case maxG >= maxR && maxG >= maxB:
+		next.g0, b.g1 = cutG, cutG
+	default:
+		next.b0, b.b1 = cutB, cutB
+	}
+
+	return next, true
+}